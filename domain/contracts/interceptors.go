@@ -13,3 +13,19 @@ type DataTransformer func([]byte) ([]byte, error)
 
 // ProgressCallback defines the contract for tracking upload/download progress.
 type ProgressCallback func(bytesTransferred, totalBytes int64)
+
+// TransferProgressCallback defines the contract for tracking downloads that may be
+// compressed. wireBytes and wireTotal count the bytes received from the network
+// (wireTotal is -1 if unknown) and decodedBytes the bytes after decompression.
+type TransferProgressCallback func(wireBytes, decodedBytes, wireTotal int64)
+
+// Codec defines the contract for encoding request bodies and decoding response bodies
+// for a single media type.
+type Codec interface {
+	// ContentType returns the media type handled by the codec, e.g. "application/json".
+	ContentType() string
+	// Encode serializes a request body.
+	Encode(v interface{}) ([]byte, error)
+	// Decode deserializes a response body into the target.
+	Decode(data []byte, target interface{}) error
+}
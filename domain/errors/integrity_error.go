@@ -0,0 +1,28 @@
+package errors
+
+import "fmt"
+
+// IntegrityError reports that a response body does not match its expected digest.
+// The body was most likely corrupted in transit, so the request is retryable.
+type IntegrityError struct {
+	// Source is where the expected digest came from, e.g. "Content-Digest" or "checksum".
+	Source    string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+// Error implements the error interface.
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("integrity check failed: %s %s mismatch (expected %s, got %s)", e.Source, e.Algorithm, e.Expected, e.Actual)
+}
+
+// NewIntegrityError creates a new IntegrityError. Digests are reported in hex.
+func NewIntegrityError(source, algorithm string, expected, actual []byte) *IntegrityError {
+	return &IntegrityError{
+		Source:    source,
+		Algorithm: algorithm,
+		Expected:  fmt.Sprintf("%x", expected),
+		Actual:    fmt.Sprintf("%x", actual),
+	}
+}
@@ -0,0 +1,24 @@
+package errors
+
+import "fmt"
+
+// OAuth2Error is an error response from an OAuth 2.0 token endpoint (RFC 6749 section 5.2).
+type OAuth2Error struct {
+	StatusCode int
+	// Code is the error code, e.g. "invalid_grant", or empty if the server did
+	// not return one.
+	Code        string
+	Description string
+	URI         string
+}
+
+// Error implements the error interface.
+func (e *OAuth2Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("oauth2: token request failed with HTTP %d", e.StatusCode)
+	}
+	if e.Description != "" {
+		return fmt.Sprintf("oauth2: %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("oauth2: %s", e.Code)
+}
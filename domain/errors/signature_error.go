@@ -0,0 +1,20 @@
+package errors
+
+import "fmt"
+
+// SignatureError reports that an HTTP message signature (RFC 9421) of a
+// response is missing or invalid.
+type SignatureError struct {
+	// Label is the label of the rejected signature, empty if there is none.
+	Label  string
+	KeyID  string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *SignatureError) Error() string {
+	if e.Label == "" {
+		return fmt.Sprintf("signature verification failed: %s", e.Reason)
+	}
+	return fmt.Sprintf("signature verification failed: %s: %s", e.Label, e.Reason)
+}
@@ -0,0 +1,12 @@
+package models
+
+// CompressionOptions configures compression of request bodies.
+type CompressionOptions struct {
+	// Encoding is the Content-Encoding applied to request bodies: "gzip", "zstd"
+	// or "br". An empty Encoding disables compression.
+	Encoding string
+
+	// MinSize is the size in bytes from which bodies are compressed.
+	// Streamed bodies of unknown length are always compressed.
+	MinSize int64
+}
@@ -4,12 +4,19 @@ import "time"
 
 // Config represents the configuration for the HTTP client.
 // This is the domain model for client configuration.
+// It doubles as a per-request override, where zero values inherit the client settings.
 type Config struct {
 	BaseURL         string
 	Timeout         time.Duration
 	Headers         map[string]string
 	StatusValidator func(int) bool
-	RetryOptions    *RetryOptions
+	// RetryOptions configures retries. Its circuit breaker settings only take
+	// effect on the client; per-request overrides keep the client's breaker.
+	RetryOptions *RetryOptions
+	Integrity    *IntegrityOptions
+	Compression  *CompressionOptions
+	// AllowAbsoluteURLs controls whether absolute request URLs may override BaseURL. Nil allows them.
+	AllowAbsoluteURLs *bool
 }
 
 // NewConfig creates a new Config with default values.
@@ -43,12 +50,27 @@ func (c *Config) Clone() *Config {
 		retryOpts = &retryOptsCopy
 	}
 
+	var integrity *IntegrityOptions
+	if c.Integrity != nil {
+		integrityCopy := *c.Integrity
+		integrity = &integrityCopy
+	}
+
+	var compression *CompressionOptions
+	if c.Compression != nil {
+		compressionCopy := *c.Compression
+		compression = &compressionCopy
+	}
+
 	return &Config{
-		BaseURL:         c.BaseURL,
-		Timeout:         c.Timeout,
-		Headers:         headers,
-		StatusValidator: c.StatusValidator,
-		RetryOptions:    retryOpts,
+		BaseURL:           c.BaseURL,
+		Timeout:           c.Timeout,
+		Headers:           headers,
+		StatusValidator:   c.StatusValidator,
+		RetryOptions:      retryOpts,
+		Integrity:         integrity,
+		Compression:       compression,
+		AllowAbsoluteURLs: c.AllowAbsoluteURLs,
 	}
 }
 
@@ -72,8 +94,32 @@ func (c *Config) Merge(other *Config) *Config {
 		merged.StatusValidator = other.StatusValidator
 	}
 
+	// The circuit breaker is client-wide, so only the retry settings are overridden
 	if other.RetryOptions != nil {
-		merged.RetryOptions = other.RetryOptions
+		retryOpts := *other.RetryOptions
+		retryOpts.CircuitBreaker = false
+		retryOpts.CircuitBreakerThreshold = 0
+		retryOpts.CircuitBreakerTimeout = 0
+		retryOpts.CircuitBreakerHalfOpenRequests = 0
+		if merged.RetryOptions != nil {
+			retryOpts.CircuitBreaker = merged.RetryOptions.CircuitBreaker
+			retryOpts.CircuitBreakerThreshold = merged.RetryOptions.CircuitBreakerThreshold
+			retryOpts.CircuitBreakerTimeout = merged.RetryOptions.CircuitBreakerTimeout
+			retryOpts.CircuitBreakerHalfOpenRequests = merged.RetryOptions.CircuitBreakerHalfOpenRequests
+		}
+		merged.RetryOptions = &retryOpts
+	}
+
+	if other.Integrity != nil {
+		merged.Integrity = other.Integrity
+	}
+
+	if other.Compression != nil {
+		merged.Compression = other.Compression
+	}
+
+	if other.AllowAbsoluteURLs != nil {
+		merged.AllowAbsoluteURLs = other.AllowAbsoluteURLs
 	}
 
 	return merged
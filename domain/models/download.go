@@ -0,0 +1,20 @@
+package models
+
+import "net/http"
+
+// DownloadResult describes a file written by a download.
+type DownloadResult struct {
+	// Path is the destination file.
+	Path string
+
+	// Size is the final size of the file in bytes.
+	Size int64
+
+	// StatusCode and Headers are those of the last response received.
+	StatusCode int
+	Headers    http.Header
+
+	// Resumed is the number of times the transfer continued from a partial file
+	// instead of starting over.
+	Resumed int
+}
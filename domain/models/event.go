@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ServerSentEvent represents a single event received from a text/event-stream.
+type ServerSentEvent struct {
+	// ID is the last event ID in effect when the event was dispatched.
+	ID string
+
+	// Event is the event type, "message" when the server did not set one.
+	Event string
+
+	// Data is the event payload, with multiple data lines joined by "\n".
+	Data string
+
+	// Retry is the reconnection time sent along with the event, 0 if none.
+	Retry time.Duration
+}
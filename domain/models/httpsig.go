@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// MessageSignatureOptions configures signing of requests with HTTP Message
+// Signatures (RFC 9421).
+type MessageSignatureOptions struct {
+	// Label names the signature in the Signature-Input and Signature fields.
+	// Default is "sig1".
+	Label string
+
+	// KeyID is sent as the keyid parameter so the server can look up the key.
+	KeyID string
+
+	// Key is the signing key, which selects the algorithm:
+	//   - []byte: hmac-sha256
+	//   - ed25519.PrivateKey: ed25519
+	//   - *ecdsa.PrivateKey on P-256: ecdsa-p256-sha256
+	//   - *rsa.PrivateKey: rsa-pss-sha512
+	Key interface{}
+
+	// Components are the covered components: derived components such as
+	// "@method", "@target-uri", "@authority", "@path" or "@query", and
+	// lower-case header names. Default is "@method", "@target-uri" and
+	// "content-digest". A Content-Digest header is computed for covered
+	// requests with a body; "content-digest" is skipped for requests without one.
+	Components []string
+
+	// Expires, if set, limits the validity of signatures with the expires parameter.
+	Expires time.Duration
+
+	// Tag is sent as the tag parameter, identifying the application profile.
+	Tag string
+}
+
+// SignatureVerifyOptions configures verification of HTTP Message Signatures
+// (RFC 9421) on responses.
+type SignatureVerifyOptions struct {
+	// Keys are the verification keys by key ID: []byte for hmac-sha256,
+	// ed25519.PublicKey, *ecdsa.PublicKey or *rsa.PublicKey. Private keys are
+	// accepted too.
+	Keys map[string]interface{}
+
+	// Label, if set, only accepts the signature with this label.
+	Label string
+
+	// RequiredComponents must be covered by the signature. Default is "@status".
+	RequiredComponents []string
+
+	// MaxAge, if set, rejects signatures created longer ago.
+	MaxAge time.Duration
+}
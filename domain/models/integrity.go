@@ -0,0 +1,12 @@
+package models
+
+// IntegrityOptions configures verification of response bodies against digests.
+type IntegrityOptions struct {
+	// VerifyDigests checks the Content-Digest and Repr-Digest (RFC 9530) and
+	// legacy Content-MD5 headers when the server sends them. Their digests
+	// cover the body as received, before decompression.
+	VerifyDigests bool
+
+	// SHA256 is the expected hex-encoded SHA-256 of the decompressed response body.
+	SHA256 string
+}
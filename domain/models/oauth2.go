@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// OAuth2Grant is an OAuth 2.0 grant type (RFC 6749).
+type OAuth2Grant string
+
+const (
+	// GrantClientCredentials authenticates as the client itself (RFC 6749 section 4.4).
+	GrantClientCredentials OAuth2Grant = "client_credentials"
+	// GrantRefreshToken exchanges a refresh token for an access token (RFC 6749 section 6).
+	GrantRefreshToken OAuth2Grant = "refresh_token"
+	// GrantPassword exchanges a resource owner's credentials (RFC 6749 section 4.3).
+	GrantPassword OAuth2Grant = "password"
+)
+
+// OAuth2AuthStyle is how client credentials are sent to the token endpoint.
+type OAuth2AuthStyle string
+
+const (
+	// AuthStyleHeader sends the client ID and secret with HTTP Basic authentication.
+	AuthStyleHeader OAuth2AuthStyle = "header"
+	// AuthStyleParams sends the client ID and secret in the request body.
+	AuthStyleParams OAuth2AuthStyle = "params"
+)
+
+// OAuth2Options configures how a client obtains and refreshes bearer tokens.
+type OAuth2Options struct {
+	// TokenURL is the token endpoint. It is used as is, not resolved against
+	// the client's base URL.
+	TokenURL string
+
+	ClientID     string
+	ClientSecret string
+
+	// Grant is the grant used to obtain a token. Default is client_credentials,
+	// or refresh_token when RefreshToken is set.
+	Grant OAuth2Grant
+
+	// Scopes are requested as a space-separated scope parameter.
+	Scopes []string
+
+	// Username and Password are the resource owner credentials of the password grant.
+	Username string
+	Password string
+
+	// RefreshToken is the initial refresh token of the refresh_token grant.
+	// Refresh tokens returned by the server replace it.
+	RefreshToken string
+
+	// Origins are additional origins, such as "https://files.example.com",
+	// that tokens are sent to. Tokens are always sent to the origin of the
+	// client's base URL; a client without one needs Origins to send tokens
+	// to absolute URLs.
+	Origins []string
+
+	// EndpointParams are additional token request parameters, e.g. "audience".
+	EndpointParams map[string]string
+
+	// AuthStyle is how the client credentials are sent. Default is AuthStyleHeader.
+	AuthStyle OAuth2AuthStyle
+
+	// ExpirySkew renews tokens this long before they expire. Default is 10 seconds.
+	ExpirySkew time.Duration
+
+	// OnToken, if set, is called with every new token, e.g. to persist a
+	// rotated refresh token.
+	OnToken func(*OAuth2Token)
+}
+
+// OAuth2Token is an access token issued by a token endpoint.
+type OAuth2Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Scope        string
+
+	// Expiry is when the access token expires, zero if it does not.
+	Expiry time.Time
+}
+
+// Valid reports whether the token has an access token that does not expire
+// within skew.
+func (t *OAuth2Token) Valid(skew time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(skew).Before(t.Expiry)
+}
@@ -0,0 +1,23 @@
+package models
+
+// ArrayFormat defines how slices are encoded in query strings.
+type ArrayFormat string
+
+const (
+	// ArrayRepeat repeats the key for each element: ids=1&ids=2.
+	ArrayRepeat ArrayFormat = "repeat"
+	// ArrayComma joins the elements with commas: ids=1,2.
+	ArrayComma ArrayFormat = "comma"
+	// ArrayBrackets appends brackets to the key: ids[]=1&ids[]=2.
+	ArrayBrackets ArrayFormat = "brackets"
+)
+
+// QueryOptions configures how parameter values are encoded in query strings.
+type QueryOptions struct {
+	// ArrayFormat is the encoding for slices and arrays. Default is ArrayRepeat.
+	ArrayFormat ArrayFormat
+
+	// TimeLayout is the layout used to format time.Time values.
+	// Default is time.RFC3339.
+	TimeLayout string
+}
@@ -1,6 +1,9 @@
 package models
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 // Response represents the HTTP response wrapper that GoFetch returns.
 // This domain model encapsulates all response information.
@@ -8,7 +11,9 @@ type Response struct {
 	StatusCode int
 	Headers    http.Header
 	Data       interface{}
-	RawBody    []byte
+
+	// RawBody is the body as received, before charset transcoding and the data transformer.
+	RawBody []byte
 }
 
 // NewResponse creates a new Response instance.
@@ -20,3 +25,44 @@ func NewResponse(statusCode int, headers http.Header, data interface{}, rawBody
 		RawBody:    rawBody,
 	}
 }
+
+// TypedResponse is the generic counterpart of Response with statically typed data.
+type TypedResponse[T any] struct {
+	StatusCode int
+	Headers    http.Header
+	Data       T
+	RawBody    []byte
+}
+
+// NewTypedResponse creates a TypedResponse from a Response and its decoded data.
+func NewTypedResponse[T any](response *Response, data T) *TypedResponse[T] {
+	return &TypedResponse[T]{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Data:       data,
+		RawBody:    response.RawBody,
+	}
+}
+
+// StreamResponse is a response whose body is handed to the caller unread.
+// The caller must close Body.
+type StreamResponse struct {
+	StatusCode    int
+	Headers       http.Header
+	ContentLength int64
+	Body          io.ReadCloser
+
+	// Uncompressed reports whether the body was transparently decompressed,
+	// in which case ContentLength and any digest headers describe the encoded body.
+	Uncompressed bool
+}
+
+// NewStreamResponse creates a new StreamResponse instance.
+func NewStreamResponse(statusCode int, headers http.Header, contentLength int64, body io.ReadCloser) *StreamResponse {
+	return &StreamResponse{
+		StatusCode:    statusCode,
+		Headers:       headers,
+		ContentLength: contentLength,
+		Body:          body,
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+// SigV4Options configures AWS Signature Version 4 request signing.
+type SigV4Options struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is the token of temporary credentials, sent as X-Amz-Security-Token.
+	SessionToken string
+
+	// Region is the signing region, e.g. "us-east-1".
+	Region string
+
+	// Service is the signing name of the service, e.g. "s3" or "execute-api".
+	Service string
+
+	// UnsignedPayload signs requests with UNSIGNED-PAYLOAD instead of the
+	// SHA-256 of the body, which is required for bodies that cannot be read
+	// twice. Only some services, such as S3, accept it.
+	UnsignedPayload bool
+}
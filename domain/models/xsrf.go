@@ -0,0 +1,26 @@
+package models
+
+// XSRFOptions configures mirroring of a CSRF token cookie into a request
+// header on unsafe methods, as expected by Django, Rails, Laravel or Angular backends.
+type XSRFOptions struct {
+	// CookieName is the cookie holding the token. Default is "XSRF-TOKEN".
+	CookieName string
+
+	// HeaderName is the header the token is sent in. Default is "X-XSRF-TOKEN".
+	HeaderName string
+
+	// BootstrapPath is requested with GET to obtain a token when none is
+	// available, and again to refresh it. The token is read from the
+	// HeaderName response header if present, otherwise from the cookie.
+	BootstrapPath string
+
+	// RefreshStatusCodes are the statuses on which the token is refreshed and
+	// the request is sent once more. Default is 403 and 419.
+	RefreshStatusCodes []int
+
+	// Origins are additional origins, such as "https://api.example.com",
+	// whose requests get the token. Requests to the origin of the client's
+	// base URL always do; a client without one needs Origins to send the
+	// token with absolute URLs.
+	Origins []string
+}
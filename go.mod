@@ -1,3 +1,10 @@
 module github.com/fourth-ally/gofetch
 
 go 1.24.3
+
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/net v0.46.0
+	golang.org/x/text v0.30.0
+)
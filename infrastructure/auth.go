@@ -0,0 +1,166 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// authChallenge is a challenge of a WWW-Authenticate header (RFC 9110 section 11.6.1).
+type authChallenge struct {
+	// scheme is the lower-cased authentication scheme, e.g. "bearer".
+	scheme string
+	// params holds the auth-params by lower-cased name.
+	params map[string]string
+}
+
+// parseChallenges parses the challenges of WWW-Authenticate header values.
+// Token68 credentials are skipped.
+func parseChallenges(values []string) []authChallenge {
+	var challenges []authChallenge
+	for _, value := range values {
+		p := &headerParser{s: value}
+		for {
+			p.skip(", \t")
+			scheme := p.token()
+			if scheme == "" {
+				break
+			}
+
+			challenge := authChallenge{scheme: strings.ToLower(scheme), params: make(map[string]string)}
+			for {
+				p.skip(" \t")
+				start := p.pos
+				name := p.token()
+				p.skip(" \t")
+				if name == "" || !p.consume('=') {
+					// The next challenge starts here
+					p.pos = start
+					break
+				}
+
+				p.skip(" \t")
+				if p.peek() == '"' {
+					challenge.params[strings.ToLower(name)] = p.quoted()
+				} else {
+					challenge.params[strings.ToLower(name)] = p.token()
+				}
+
+				p.skip(" \t")
+				if !p.consume(',') {
+					break
+				}
+			}
+			challenges = append(challenges, challenge)
+		}
+	}
+	return challenges
+}
+
+// findChallenge returns the first challenge with the given lower-cased scheme.
+func findChallenge(header http.Header, scheme string) (authChallenge, bool) {
+	for _, challenge := range parseChallenges(header.Values("WWW-Authenticate")) {
+		if challenge.scheme == scheme {
+			return challenge, true
+		}
+	}
+	return authChallenge{}, false
+}
+
+// headerParser scans tokens and quoted strings of a structured header value.
+type headerParser struct {
+	s   string
+	pos int
+}
+
+func (p *headerParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *headerParser) consume(c byte) bool {
+	if p.peek() == c && c != 0 {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *headerParser) skip(chars string) {
+	for p.pos < len(p.s) && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// token reads an RFC 9110 token.
+func (p *headerParser) token() string {
+	start := p.pos
+	for p.pos < len(p.s) && isTokenChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// quoted reads a quoted string, removing the quotes and escapes.
+func (p *headerParser) quoted() string {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '"':
+			return b.String()
+		case c == '\\' && p.pos < len(p.s):
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// canReplay reports whether a sent request can be sent again: it has no body
+// or its body can be reopened.
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// replayRequest copies a sent request with a fresh body.
+func replayRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// closeRequestBody closes the body of a request that will not be sent.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// discardResponse drains and closes a superseded response so its connection
+// can be reused.
+func discardResponse(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxStreamErrorBody))
+	resp.Body.Close()
+}
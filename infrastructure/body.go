@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// readerBody streams an io.Reader or a reader factory as the request body.
+type readerBody struct {
+	source   func() (io.ReadCloser, error)
+	length   int64
+	canRetry bool
+}
+
+// requestBody returns body, or nil if it is a nil pointer to a reader or
+// stream body, or a nil reader factory, so that it is sent as no body like an
+// untyped nil instead of panicking when it is read.
+func requestBody(body interface{}) interface{} {
+	switch body.(type) {
+	case streamBody, io.Reader, func() (io.ReadCloser, error):
+		if v := reflect.ValueOf(body); (v.Kind() == reflect.Pointer || v.Kind() == reflect.Func) && v.IsNil() {
+			return nil
+		}
+	}
+	return body
+}
+
+// newStreamBody returns the streamBody for a request body, or nil if the body
+// is serialized by a codec instead. Readers are streamed as they are: seekable
+// readers are rewound for every attempt, a func() (io.ReadCloser, error) is
+// called for every attempt, and any other reader can only be sent once.
+// Like net/http, a *bytes.Buffer is sent from a snapshot of its contents.
+func newStreamBody(body interface{}) streamBody {
+	switch b := body.(type) {
+	case streamBody:
+		return b
+	case *bytes.Buffer:
+		return newStreamBody(bytes.NewReader(b.Bytes()))
+	case func() (io.ReadCloser, error):
+		return &readerBody{source: b, length: -1, canRetry: true}
+	case io.Reader:
+		source, length, canRetry := readerSource(b)
+		return &readerBody{source: source, length: length, canRetry: canRetry}
+	default:
+		return nil
+	}
+}
+
+// open implements streamBody.
+func (rb *readerBody) open() (io.ReadCloser, error) {
+	return rb.source()
+}
+
+// contentType implements streamBody. The request Content-Type is kept, or
+// application/octet-stream is used if none is set.
+func (rb *readerBody) contentType() string {
+	return ""
+}
+
+// size implements streamBody.
+func (rb *readerBody) size() int64 {
+	return rb.length
+}
+
+// replayable implements streamBody.
+func (rb *readerBody) replayable() bool {
+	return rb.canRetry
+}
+
+// payloadBody is a request body that reports upload progress. Signers and
+// Digest auth-int hash the payload through open instead of GetBody, so the
+// bytes they read are not reported as uploaded.
+type payloadBody struct {
+	io.ReadCloser
+	open func() (io.ReadCloser, error)
+}
+
+// openPayload opens a copy of the body of a request for hashing. The body
+// must be replayable, with a GetBody function.
+func openPayload(req *http.Request) (io.ReadCloser, error) {
+	if body, ok := req.Body.(*payloadBody); ok {
+		return body.open()
+	}
+	return req.GetBody()
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// Byte order marks recognized when sniffing the charset of a body.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// xmlEncodingDecl matches the encoding declaration of an XML prolog.
+var xmlEncodingDecl = regexp.MustCompile(`^(\s*<\?xml[^>]*?\sencoding\s*=\s*)("[^"]*"|'[^']*')`)
+
+// transcodeToUTF8 converts a response body to UTF-8 using a byte order mark
+// or the charset parameter of its Content-Type, in that order of precedence.
+// Byte order marks are only sniffed for textual media types. Bodies that are
+// already UTF-8 or declare an unknown charset are returned unchanged, minus a
+// UTF-8 byte order mark.
+func transcodeToUTF8(body []byte, contentType string) ([]byte, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	label := params["charset"]
+
+	if label != "" || isTextMediaType(mediaType) {
+		switch {
+		case bytes.HasPrefix(body, bomUTF8):
+			return body[len(bomUTF8):], nil
+		case bytes.HasPrefix(body, bomUTF16BE):
+			body, label = body[len(bomUTF16BE):], "utf-16be"
+		case bytes.HasPrefix(body, bomUTF16LE):
+			body, label = body[len(bomUTF16LE):], "utf-16le"
+		}
+	}
+	if label == "" {
+		return body, nil
+	}
+
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return body, nil
+	}
+	if name, _ := htmlindex.Name(encoding); name == "utf-8" {
+		return body, nil
+	}
+
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", label, err)
+	}
+
+	// The prolog of a transcoded XML document would still declare the old charset
+	if strings.HasSuffix(mediaType, "xml") {
+		decoded = xmlEncodingDecl.ReplaceAll(decoded, []byte(`${1}"UTF-8"`))
+	}
+
+	return decoded, nil
+}
+
+// isTextMediaType reports whether a media type is textual, so that its
+// body may start with a byte order mark.
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "/json"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	case strings.HasSuffix(mediaType, "/xml"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	case mediaType == ContentTypeForm, mediaType == "application/x-ndjson":
+		return true
+	default:
+		return false
+	}
+}
+
+// charsetReader decodes XML documents that declare a non-UTF-8 encoding in their prolog.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported XML encoding %q", label)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
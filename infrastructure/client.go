@@ -3,12 +3,10 @@ package infrastructure
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/fourth-ally/gofetch/domain/contracts"
@@ -25,8 +23,17 @@ type Client struct {
 	dataTransformer      contracts.DataTransformer
 	uploadProgress       contracts.ProgressCallback
 	downloadProgress     contracts.ProgressCallback
+	transferProgress     contracts.TransferProgressCallback
 	retryManager         *RetryManager
 	circuitBreaker       *CircuitBreaker
+	codecs               *CodecRegistry
+	queryOptions         *models.QueryOptions
+	xsrf                 *xsrfGuard
+	oauth2               *oauth2Source
+	digest               *digestAuth
+	sigv4                *SigV4Signer
+	messageSigner        *MessageSigner
+	messageVerifier      *MessageVerifier
 }
 
 // NewClient creates a new GoFetch client instance.
@@ -36,6 +43,7 @@ func NewClient() *Client {
 		config:               models.NewConfig(),
 		requestInterceptors:  make([]contracts.RequestInterceptor, 0),
 		responseInterceptors: make([]contracts.ResponseInterceptor, 0),
+		codecs:               NewCodecRegistry(),
 	}
 }
 
@@ -45,6 +53,14 @@ func (c *Client) SetBaseURL(baseURL string) *Client {
 	return c
 }
 
+// SetAllowAbsoluteURLs sets whether absolute request URLs, such as links
+// returned by an API, may override the base URL. They are allowed by default;
+// when denied, requesting an absolute URL fails if a base URL is set.
+func (c *Client) SetAllowAbsoluteURLs(allow bool) *Client {
+	c.config.AllowAbsoluteURLs = &allow
+	return c
+}
+
 // SetTimeout sets the timeout for requests.
 func (c *Client) SetTimeout(timeout time.Duration) *Client {
 	c.config.Timeout = timeout
@@ -94,6 +110,13 @@ func (c *Client) SetDownloadProgress(callback contracts.ProgressCallback) *Clien
 	return c
 }
 
+// SetTransferProgress sets a download progress callback that reports both the
+// bytes received from the network and the bytes after decompression.
+func (c *Client) SetTransferProgress(callback contracts.TransferProgressCallback) *Client {
+	c.transferProgress = callback
+	return c
+}
+
 // SetRetryOptions configures retry behavior for the client.
 func (c *Client) SetRetryOptions(options *models.RetryOptions) *Client {
 	c.config.RetryOptions = options
@@ -115,16 +138,28 @@ func (c *Client) SetRetryOptions(options *models.RetryOptions) *Client {
 
 // NewInstance creates a new client instance inheriting all settings from the current client.
 func (c *Client) NewInstance() *Client {
+	// Copy the HTTP client so the cookie jar and transport are shared
+	httpClient := *c.httpClient
+
 	newClient := &Client{
-		httpClient:           &http.Client{Timeout: c.config.Timeout},
+		httpClient:           &httpClient,
 		config:               c.config.Clone(),
 		requestInterceptors:  make([]contracts.RequestInterceptor, len(c.requestInterceptors)),
 		responseInterceptors: make([]contracts.ResponseInterceptor, len(c.responseInterceptors)),
 		dataTransformer:      c.dataTransformer,
 		uploadProgress:       c.uploadProgress,
 		downloadProgress:     c.downloadProgress,
+		transferProgress:     c.transferProgress,
 		retryManager:         c.retryManager,
 		circuitBreaker:       c.circuitBreaker,
+		codecs:               c.codecs.Clone(),
+		queryOptions:         c.queryOptions,
+		xsrf:                 c.xsrf,
+		oauth2:               c.oauth2,
+		digest:               c.digest,
+		sigv4:                c.sigv4,
+		messageSigner:        c.messageSigner,
+		messageVerifier:      c.messageVerifier,
 	}
 
 	copy(newClient.requestInterceptors, c.requestInterceptors)
@@ -133,100 +168,186 @@ func (c *Client) NewInstance() *Client {
 	return newClient
 }
 
-// buildURL constructs the full URL from base URL, path, and parameters.
-func (c *Client) buildURL(path string, params map[string]interface{}) (string, error) {
-	// Start with base URL or empty string
-	fullURL := c.config.BaseURL
+// resolveConfig merges a per-request configuration over the client configuration.
+func (c *Client) resolveConfig(requestConfig *models.Config) *models.Config {
+	if requestConfig == nil {
+		return c.config
+	}
+	return c.config.Merge(requestConfig)
+}
 
-	// Handle path parameters (e.g., /users/:id)
-	processedPath := path
+// mergeRequestConfigs folds the per-request configurations passed to the HTTP
+// methods into a single config, later configs taking precedence.
+func mergeRequestConfigs(configs []*models.Config) *models.Config {
+	var merged *models.Config
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+		if merged == nil {
+			merged = config
+			continue
+		}
+		merged = merged.Merge(config)
+	}
+	return merged
+}
+
+// httpClientFor returns the HTTP client to use for the given configuration.
+// The shared client is copied only when the timeout is overridden per request.
+func (c *Client) httpClientFor(config *models.Config) *http.Client {
+	if config.Timeout == c.httpClient.Timeout {
+		return c.httpClient
+	}
+	httpClient := *c.httpClient
+	httpClient.Timeout = config.Timeout
+	return &httpClient
+}
+
+// buildURL constructs the full URL of a request from the base URL, path template and parameters.
+func (c *Client) buildURL(config *models.Config, r *Request) (string, error) {
 	queryParams := url.Values{}
+	encoder := newQueryEncoder(c.queryOptions)
+
+	// Handle path parameters (e.g., /users/:id or /users/{id})
+	used := make(map[string]bool)
+	processedPath, err := expandPath(r.path, encoder, func(name string) (interface{}, bool) {
+		value, ok := r.pathParams[name]
+		if !ok {
+			value, ok = r.params[name]
+		}
+		used[name] = ok
+		return value, ok && value != nil
+	})
+	if err != nil {
+		return "", err
+	}
 
-	if params != nil {
-		for key, value := range params {
-			placeholder := ":" + key
-			if strings.Contains(processedPath, placeholder) {
-				// Replace path parameter
-				processedPath = strings.Replace(processedPath, placeholder, fmt.Sprintf("%v", value), -1)
-			} else {
-				// Add to query string
-				queryParams.Add(key, fmt.Sprintf("%v", value))
-			}
+	for key := range r.pathParams {
+		if !used[key] {
+			return "", fmt.Errorf("unknown path parameter %q", key)
 		}
 	}
 
-	// Combine base URL and path
-	if fullURL != "" && processedPath != "" {
-		fullURL = strings.TrimRight(fullURL, "/") + "/" + strings.TrimLeft(processedPath, "/")
-	} else if processedPath != "" {
-		fullURL = processedPath
+	// Parameters without a placeholder are added to the query string
+	for key, value := range r.params {
+		if used[key] {
+			continue
+		}
+		if err := encoder.encodeParam(queryParams, key, value); err != nil {
+			return "", err
+		}
+	}
+
+	// Add explicit query values
+	for _, value := range r.query {
+		if err := encoder.encodeParam(queryParams, value.key, value.value); err != nil {
+			return "", err
+		}
 	}
 
-	// Add query parameters
+	// Resolve the path against the base URL
+	allowAbsolute := config.AllowAbsoluteURLs == nil || *config.AllowAbsoluteURLs
+	fullURL, err := resolveURL(config.BaseURL, processedPath, allowAbsolute)
+	if err != nil {
+		return "", err
+	}
+
+	// Add query parameters after the queries of the base URL and path
 	if len(queryParams) > 0 {
-		fullURL += "?" + queryParams.Encode()
+		fullURL.RawQuery = joinQuery(fullURL.RawQuery, queryParams.Encode())
 	}
 
-	return fullURL, nil
+	return fullURL.String(), nil
 }
 
 // executeRequestWithRetry wraps executeRequest with retry logic and circuit breaker.
-func (c *Client) executeRequestWithRetry(ctx context.Context, method, path string, params map[string]interface{}, body interface{}, target interface{}, requestConfig *models.Config) (*models.Response, error) {
+func (c *Client) executeRequestWithRetry(ctx context.Context, r *Request) (*models.Response, error) {
+	return executeWithRetry(ctx, c, r, func(config *models.Config) (*models.Response, int, error) {
+		resp, err := c.executeRequest(ctx, r, config)
+		if resp == nil {
+			return nil, 0, err
+		}
+		return resp, resp.StatusCode, err
+	}, nil)
+}
+
+// executeWithRetry runs attempts of a request under its retry options and the
+// client's circuit breaker. Each attempt reports the status code it received
+// (0 if none). discard, if set, releases the result of an attempt that is
+// superseded by a retry.
+func executeWithRetry[T any](ctx context.Context, c *Client, r *Request, attempt func(config *models.Config) (T, int, error), discard func(T)) (T, error) {
+	var zero T
+
+	// Merge configurations
+	config := c.resolveConfig(r.config)
+
+	// Per-request retry options get their own retry manager
+	retryManager := c.retryManager
+	if r.config != nil && r.config.RetryOptions != nil {
+		retryManager = NewRetryManager(r.config.RetryOptions)
+	}
+
 	// Check if retries or circuit breaker are configured
-	hasRetries := c.retryManager != nil && c.config.RetryOptions != nil && c.config.RetryOptions.MaxRetries > 0
+	hasRetries := retryManager != nil && config.RetryOptions != nil && config.RetryOptions.MaxRetries > 0
 	hasCircuitBreaker := c.circuitBreaker != nil
 
+	// A one-shot body cannot be sent again, so retries are disabled explicitly
+	// rather than resending it empty
+	if r.stream != nil && !r.stream.replayable() {
+		hasRetries = false
+	}
+
 	// If neither retry nor circuit breaker is configured, execute directly
 	if !hasRetries && !hasCircuitBreaker {
-		return c.executeRequest(ctx, method, path, params, body, target, requestConfig)
+		result, _, err := attempt(config)
+		return result, err
 	}
 
 	// Build URL for circuit breaker endpoint tracking
-	fullURL, err := c.buildURL(path, params)
+	fullURL, err := c.buildURL(config, r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build URL: %w", err)
+		return zero, fmt.Errorf("failed to build URL: %w", err)
 	}
 
 	// Check circuit breaker before attempting
 	if hasCircuitBreaker {
 		if c.circuitBreaker.IsOpen(fullURL) {
-			return nil, fmt.Errorf("circuit breaker is open for endpoint: %s", fullURL)
+			return zero, fmt.Errorf("circuit breaker is open for endpoint: %s", fullURL)
 		}
 
 		if !c.circuitBreaker.CanAttempt(fullURL) {
-			return nil, fmt.Errorf("circuit breaker: too many requests in half-open state for: %s", fullURL)
+			return zero, fmt.Errorf("circuit breaker: too many requests in half-open state for: %s", fullURL)
 		}
 	}
 
 	// Determine max attempts (at least 1 even if no retries)
 	maxAttempts := 0
 	if hasRetries {
-		maxAttempts = c.config.RetryOptions.MaxRetries
+		maxAttempts = config.RetryOptions.MaxRetries
 	}
 
 	var lastErr error
-	var lastResponse *models.Response
+	var lastResult T
 	var lastStatusCode int
 
 	// Retry loop
-	for attempt := 0; attempt <= maxAttempts; attempt++ {
+	for i := 0; i <= maxAttempts; i++ {
 		// Execute request
-		resp, err := c.executeRequest(ctx, method, path, params, body, target, requestConfig)
+		result, statusCode, err := attempt(config)
 
 		// Success case
-		if err == nil && (resp == nil || resp.StatusCode < 500) {
+		if err == nil && statusCode < 500 {
 			if hasCircuitBreaker {
 				c.circuitBreaker.RecordSuccess(fullURL)
 			}
-			return resp, nil
+			return result, nil
 		}
 
 		// Store error and response details
 		lastErr = err
-		lastResponse = resp
-		if resp != nil {
-			lastStatusCode = resp.StatusCode
-		}
+		lastResult = result
+		lastStatusCode = statusCode
 
 		// Check if error is retryable
 		httpErr, isHTTPError := err.(*errors.HTTPError)
@@ -240,159 +361,426 @@ func (c *Client) executeRequestWithRetry(ctx context.Context, method, path strin
 
 			// Check if circuit just opened
 			if c.circuitBreaker.IsOpen(fullURL) {
-				return nil, fmt.Errorf("circuit breaker opened after attempt %d: %w", attempt+1, lastErr)
+				if err == nil && discard != nil {
+					discard(result)
+				}
+				return zero, fmt.Errorf("circuit breaker opened after attempt %d: %w", i+1, lastErr)
 			}
 		}
 
 		// If no retries configured, return immediately after first attempt
 		if !hasRetries {
-			return resp, err
+			return result, err
 		}
 
 		// Check if we should retry
-		shouldRetry := c.retryManager.ShouldRetry(attempt, lastStatusCode, err)
+		shouldRetry := retryManager.ShouldRetry(i, lastStatusCode, err)
 
 		// Don't retry on last attempt or if not retryable
-		if !shouldRetry || attempt == c.config.RetryOptions.MaxRetries {
+		if !shouldRetry || i == config.RetryOptions.MaxRetries {
 			break
 		}
 
+		// Release the superseded result before retrying
+		if err == nil && discard != nil {
+			discard(result)
+		}
+
 		// Wait before retry (with backoff and jitter)
-		c.retryManager.Wait(attempt)
+		retryManager.Wait(i)
 
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("request cancelled during retry: %w", ctx.Err())
+			return zero, fmt.Errorf("request cancelled during retry: %w", ctx.Err())
 		default:
 			// Continue to next attempt
 		}
 	}
 
 	// Return the last error after all retries exhausted
-	return lastResponse, lastErr
+	return lastResult, lastErr
 }
 
 // executeRequest executes an HTTP request with all interceptors and error handling.
-func (c *Client) executeRequest(ctx context.Context, method, path string, params map[string]interface{}, body interface{}, target interface{}, requestConfig *models.Config) (*models.Response, error) {
-	// Merge configurations
-	config := c.config
-	if requestConfig != nil {
-		config = c.config.Merge(requestConfig)
+// The config passed in is the already merged configuration for this request.
+func (c *Client) executeRequest(ctx context.Context, r *Request, config *models.Config) (*models.Response, error) {
+	resp, err := c.sendRequest(ctx, r, config, c.httpClientFor(config))
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Build URL
-	fullURL, err := c.buildURL(path, params)
+	// Only verify bodies that are accepted and actually carry content
+	var verifier *bodyVerifier
+	if r.method != http.MethodHead && config.StatusValidator(resp.StatusCode) {
+		verifier, err = newBodyVerifier(config.Integrity, resp.Header, responseScope(resp.StatusCode), resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Read response body with progress tracking and integrity verification
+	reader := &progressReader{
+		reader:   resp.Body,
+		total:    resp.ContentLength,
+		verifier: verifier,
+	}
+	if !c.trackDecoded(resp.Body) {
+		if resp.ContentLength > 0 {
+			reader.callback = c.downloadProgress
+		}
+		reader.transfer = c.transferProgress
+	}
+
+	respBody, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build URL: %w", err)
+		if integrityErr, ok := err.(*errors.IntegrityError); ok {
+			return nil, integrityErr
+		}
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Prepare request body
-	var bodyReader io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
+	// Validate status code
+	if !config.StatusValidator(resp.StatusCode) {
+		return nil, errors.NewHTTPError(resp, respBody, "")
+	}
+
+	// Transcode to UTF-8, keeping the raw body as received
+	data, err := transcodeToUTF8(respBody, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply data transformer if set
+	if c.dataTransformer != nil {
+		data, err = c.dataTransformer(data)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("data transformer error: %w", err)
 		}
-		bodyReader = bytes.NewBuffer(jsonData)
+	}
 
-		// Wrap with progress tracking if callback is set
-		if c.uploadProgress != nil {
-			bodyReader = &progressReader{
-				reader:   bodyReader,
-				total:    int64(len(jsonData)),
-				callback: c.uploadProgress,
-			}
+	// Decode response into target with the codec for its content type
+	if r.target != nil && len(data) > 0 {
+		if err := c.decodeBody(data, resp.Header.Get("Content-Type"), r.target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	return models.NewResponse(resp.StatusCode, resp.Header, r.target, respBody), nil
+}
+
+// newRequest builds the Request used by the HTTP method shortcuts.
+func (c *Client) newRequest(method, path string, params map[string]interface{}, body interface{}, target interface{}, configs []*models.Config) *Request {
+	body = requestBody(body)
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		params: params,
+		body:   body,
+		stream: newStreamBody(body),
+		target: target,
+		config: mergeRequestConfigs(configs),
+	}
+}
+
+// sendRequest builds the HTTP request, runs the request interceptors, sends it
+// and runs the response interceptors. The caller must close the response body.
+func (c *Client) sendRequest(ctx context.Context, r *Request, config *models.Config, httpClient *http.Client) (*http.Response, error) {
+	// Build URL
+	fullURL, err := c.buildURL(config, r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to build URL: %w", err)
 	}
 
 	// Set default headers
+	headers := make(http.Header, len(config.Headers)+2)
 	for key, value := range config.Headers {
-		req.Header.Set(key, value)
+		headers.Set(key, value)
+	}
+
+	// Advertise the registered codecs
+	if headers.Get("Accept") == "" {
+		headers.Set("Accept", c.codecs.Accept())
+	}
+
+	// Advertise the supported content codings, decoded below
+	negotiateEncoding(headers)
+
+	// Prepare request body
+	bodyReader, contentLength, getBody, err := c.prepareBody(r, headers, config.Compression)
+	if err != nil {
+		return nil, err
 	}
 
-	// Set content type for body requests
-	if body != nil && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, r.method, fullURL, bodyReader)
+	if err != nil {
+		if closer, ok := bodyReader.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = headers
+	if bodyReader != nil {
+		req.ContentLength = contentLength
+	}
+
+	if getBody != nil {
+		req.GetBody = getBody
+	}
+
+	// Authenticate with an OAuth2 bearer token unless the caller did
+	var bearer string
+	if c.oauth2 != nil && req.Header.Get("Authorization") == "" {
+		if bearer, err = c.oauth2.apply(ctx, c, req, config.BaseURL); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	}
+
+	// Mirror the XSRF token cookie on unsafe methods
+	xsrfApplied := false
+	if c.xsrf != nil {
+		if xsrfApplied, err = c.xsrf.apply(ctx, c, req, config.BaseURL); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
 	}
 
 	// Apply request interceptors
 	for _, interceptor := range c.requestInterceptors {
-		req, err = interceptor(req)
+		intercepted, err := interceptor(req)
 		if err != nil {
+			// Stop the writers of streamed bodies
+			closeRequestBody(req)
 			return nil, fmt.Errorf("request interceptor error: %w", err)
 		}
+		req = intercepted
+	}
+
+	// Answer a cached Digest challenge for the final method and URI
+	digestPending := c.digest != nil && req.Header.Get("Authorization") == ""
+	if digestPending {
+		if err := c.digest.authorize(req); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
 	}
 
-	// Execute request
-	resp, err := c.httpClient.Do(req)
+	// Execute request, signing it and any replay last. Replays are copies of
+	// the request sent last, so they keep the credentials of earlier replays.
+	sent := req
+	send := func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return c.send(httpClient, req)
+	}
+	resp, err := send(req)
 	if err != nil {
 		return nil, fmt.Errorf("request execution error: %w", err)
 	}
-	defer resp.Body.Close()
+
+	// Answer a Digest challenge and send the request once more
+	if digestPending {
+		if resp, err = c.digest.resend(ctx, req, resp, send); err != nil {
+			return nil, fmt.Errorf("request execution error: %w", err)
+		}
+	}
+
+	// Renew a rejected OAuth2 token and send the request once more
+	if bearer != "" {
+		if resp, err = c.oauth2.resend(ctx, c, sent, resp, send, bearer); err != nil {
+			return nil, fmt.Errorf("request execution error: %w", err)
+		}
+	}
+
+	// Refresh a rejected XSRF token and send the request once more
+	if xsrfApplied {
+		resend := send
+		if digestPending {
+			// Answer the Digest challenge again with the next nonce count
+			resend = func(req *http.Request) (*http.Response, error) {
+				if err := c.digest.authorize(req); err != nil {
+					closeRequestBody(req)
+					return nil, err
+				}
+				return send(req)
+			}
+		}
+		if resp, err = c.xsrf.resend(ctx, c, sent, resp, resend); err != nil {
+			return nil, fmt.Errorf("request execution error: %w", err)
+		}
+	}
+
+	// Verify the response signature against the headers and body as received
+	if c.messageVerifier != nil && config.StatusValidator(resp.StatusCode) {
+		if err := c.messageVerifier.Verify(resp); err != nil {
+			discardResponse(resp)
+			return nil, err
+		}
+	}
+
+	// Decode the content codings advertised above
+	decodeNegotiated(req, resp)
 
 	// Apply response interceptors
 	for _, interceptor := range c.responseInterceptors {
-		resp, err = interceptor(resp)
+		intercepted, err := interceptor(resp)
 		if err != nil {
+			resp.Body.Close()
 			return nil, fmt.Errorf("response interceptor error: %w", err)
 		}
+		resp = intercepted
 	}
 
-	// Read response body with progress tracking
-	var respBody []byte
-	if c.downloadProgress != nil && resp.ContentLength > 0 {
-		progressReader := &progressReader{
-			reader:   resp.Body,
-			total:    resp.ContentLength,
-			callback: c.downloadProgress,
+	return resp, nil
+}
+
+// send signs a request with SigV4 and HTTP Message Signatures, if
+// configured, and sends it.
+func (c *Client) send(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	if c.sigv4 != nil {
+		if err := c.sigv4.Sign(req, time.Now()); err != nil {
+			closeRequestBody(req)
+			return nil, err
 		}
-		respBody, err = io.ReadAll(progressReader)
-	} else {
-		respBody, err = io.ReadAll(resp.Body)
 	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if c.messageSigner != nil {
+		if err := c.messageSigner.Sign(req, time.Now()); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
 	}
+	return httpClient.Do(req)
+}
 
-	// Validate status code
-	if !config.StatusValidator(resp.StatusCode) {
-		return nil, errors.NewHTTPError(resp, respBody, "")
+// prepareBody turns a request body into a reader, setting its Content-Type
+// and, if compressed, its Content-Encoding. Streaming bodies are opened fresh
+// for every attempt; other bodies are serialized with the codec for the
+// request Content-Type. The returned length is -1 when unknown, and getBody,
+// if not nil, reopens the body for redirects. Bodies that report upload
+// progress are wrapped in a payloadBody, so they can be hashed without it.
+func (c *Client) prepareBody(r *Request, headers http.Header, compression *models.CompressionOptions) (io.Reader, int64, func() (io.ReadCloser, error), error) {
+	if r.body == nil {
+		return nil, 0, nil, nil
 	}
 
-	// Apply data transformer if set
-	if c.dataTransformer != nil {
-		respBody, err = c.dataTransformer(respBody)
+	if r.stream != nil {
+		stream := r.stream
+		if contentType := stream.contentType(); contentType != "" {
+			headers.Set("Content-Type", contentType)
+		} else if headers.Get("Content-Type") == "" {
+			headers.Set("Content-Type", ContentTypeBytes)
+		}
+
+		encoding, err := requestEncoding(compression, headers, stream, stream.size())
 		if err != nil {
-			return nil, fmt.Errorf("data transformer error: %w", err)
+			return nil, 0, nil, err
+		}
+
+		// Progress counts the uncompressed bytes read from the source
+		encode := func(track bool) (io.ReadCloser, error) {
+			reader, err := stream.open()
+			if err != nil {
+				return nil, err
+			}
+			if track {
+				reader = c.trackUpload(stream, reader, stream.size())
+			}
+			if encoding != "" {
+				reader = compressStream(reader, encoding)
+			}
+			return reader, nil
 		}
+		// Signers hash the payload without reporting progress
+		open := func() (io.ReadCloser, error) {
+			reader, err := encode(true)
+			if err != nil || c.uploadProgress == nil || !stream.replayable() {
+				return reader, err
+			}
+			return &payloadBody{ReadCloser: reader, open: func() (io.ReadCloser, error) {
+				return encode(false)
+			}}, nil
+		}
+
+		reader, err := open()
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to open request body: %w", err)
+		}
+
+		length := stream.size()
+		if encoding != "" {
+			headers.Set("Content-Encoding", encoding)
+			length = -1
+		}
+
+		// Let redirects replay streaming bodies
+		if !stream.replayable() {
+			open = nil
+		}
+		return reader, length, open, nil
 	}
 
-	// Unmarshal response into target if provided
-	if target != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, target); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	data, contentType, err := c.encodeBody(r.body, headers.Get("Content-Type"))
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	headers.Set("Content-Type", contentType)
+
+	encoding, err := requestEncoding(compression, headers, r.body, int64(len(data)))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if encoding != "" {
+		if data, err = compressBytes(data, encoding); err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to compress request body: %w", err)
 		}
+		headers.Set("Content-Encoding", encoding)
+	}
+
+	if c.uploadProgress == nil {
+		return bytes.NewBuffer(data), int64(len(data)), nil, nil
+	}
+	open := func() (io.ReadCloser, error) {
+		return &payloadBody{
+			ReadCloser: c.trackUpload(r.body, io.NopCloser(bytes.NewReader(data)), int64(len(data))),
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(data)), nil
+			},
+		}, nil
+	}
+	reader, _ := open()
+	return reader, int64(len(data)), open, nil
+}
+
+// trackUpload wraps a request body with upload progress tracking if a callback is set.
+func (c *Client) trackUpload(body interface{}, reader io.ReadCloser, length int64) io.ReadCloser {
+	if c.uploadProgress == nil {
+		return reader
 	}
 
-	return models.NewResponse(resp.StatusCode, resp.Header, target, respBody), nil
+	progress := &progressReader{
+		reader:   reader,
+		total:    length,
+		callback: c.uploadProgress,
+	}
+	if part, ok := body.(uploadPart); ok {
+		progress.transferred, progress.total = part.uploadOffset()
+	}
+	return progress
 }
 
 // Get performs a GET request.
-func (c *Client) Get(ctx context.Context, path string, params map[string]interface{}, target interface{}) (*models.Response, error) {
-	return c.executeRequestWithRetry(ctx, http.MethodGet, path, params, nil, target, nil)
+// Optional per-request configs are merged over the client configuration in order.
+func (c *Client) Get(ctx context.Context, path string, params map[string]interface{}, target interface{}, configs ...*models.Config) (*models.Response, error) {
+	return c.executeRequestWithRetry(ctx, c.newRequest(http.MethodGet, path, params, nil, target, configs))
 }
 
 // Post performs a POST request.
-func (c *Client) Post(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}) (*models.Response, error) {
-	return c.executeRequestWithRetry(ctx, http.MethodPost, path, params, body, target, nil)
+// Optional per-request configs are merged over the client configuration in order.
+func (c *Client) Post(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}, configs ...*models.Config) (*models.Response, error) {
+	return c.executeRequestWithRetry(ctx, c.newRequest(http.MethodPost, path, params, body, target, configs))
 }
 
 // Config returns the client configuration for testing purposes.
@@ -401,16 +789,19 @@ func (c *Client) Config() *models.Config {
 }
 
 // Put performs a PUT request.
-func (c *Client) Put(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}) (*models.Response, error) {
-	return c.executeRequestWithRetry(ctx, http.MethodPut, path, params, body, target, nil)
+// Optional per-request configs are merged over the client configuration in order.
+func (c *Client) Put(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}, configs ...*models.Config) (*models.Response, error) {
+	return c.executeRequestWithRetry(ctx, c.newRequest(http.MethodPut, path, params, body, target, configs))
 }
 
 // Patch performs a PATCH request.
-func (c *Client) Patch(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}) (*models.Response, error) {
-	return c.executeRequestWithRetry(ctx, http.MethodPatch, path, params, body, target, nil)
+// Optional per-request configs are merged over the client configuration in order.
+func (c *Client) Patch(ctx context.Context, path string, params map[string]interface{}, body interface{}, target interface{}, configs ...*models.Config) (*models.Response, error) {
+	return c.executeRequestWithRetry(ctx, c.newRequest(http.MethodPatch, path, params, body, target, configs))
 }
 
 // Delete performs a DELETE request.
-func (c *Client) Delete(ctx context.Context, path string, params map[string]interface{}, target interface{}) (*models.Response, error) {
-	return c.executeRequestWithRetry(ctx, http.MethodDelete, path, params, nil, target, nil)
+// Optional per-request configs are merged over the client configuration in order.
+func (c *Client) Delete(ctx context.Context, path string, params map[string]interface{}, target interface{}, configs ...*models.Config) (*models.Response, error) {
+	return c.executeRequestWithRetry(ctx, c.newRequest(http.MethodDelete, path, params, nil, target, configs))
 }
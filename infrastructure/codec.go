@@ -0,0 +1,332 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/fourth-ally/gofetch/domain/contracts"
+)
+
+// Media types of the built-in codecs.
+const (
+	ContentTypeJSON  = "application/json"
+	ContentTypeXML   = "application/xml"
+	ContentTypeForm  = "application/x-www-form-urlencoded"
+	ContentTypeText  = "text/plain"
+	ContentTypeBytes = "application/octet-stream"
+)
+
+// CodecRegistry selects codecs by media type.
+// Codecs are kept in registration order, which is also their order in the Accept header.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	codecs []contracts.Codec
+}
+
+// NewCodecRegistry creates a registry with the built-in JSON, XML, form,
+// text and raw bytes codecs.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		codecs: []contracts.Codec{
+			JSONCodec{},
+			XMLCodec{},
+			FormCodec{},
+			TextCodec{},
+			BytesCodec{},
+		},
+	}
+}
+
+// Register adds a codec, replacing any codec registered for the same media type.
+func (cr *CodecRegistry) Register(codec contracts.Codec) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	mediaType := normalizeMediaType(codec.ContentType())
+	for i, existing := range cr.codecs {
+		if normalizeMediaType(existing.ContentType()) == mediaType {
+			cr.codecs[i] = codec
+			return
+		}
+	}
+	cr.codecs = append(cr.codecs, codec)
+}
+
+// Lookup returns the codec for a Content-Type value, or nil if none matches.
+// Structured syntax suffixes such as "application/problem+json" resolve to
+// the codec of their base type.
+func (cr *CodecRegistry) Lookup(contentType string) contracts.Codec {
+	mediaType := normalizeMediaType(contentType)
+	if mediaType == "" {
+		return nil
+	}
+
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	if codec := cr.find(mediaType); codec != nil {
+		return codec
+	}
+
+	// Fall back to the structured syntax suffix (RFC 6839)
+	if i := strings.LastIndex(mediaType, "+"); i != -1 {
+		switch mediaType[i+1:] {
+		case "json":
+			return cr.find(ContentTypeJSON)
+		case "xml":
+			return cr.find(ContentTypeXML)
+		}
+	}
+
+	return nil
+}
+
+// find returns the codec registered for an exact media type.
+func (cr *CodecRegistry) find(mediaType string) contracts.Codec {
+	for _, codec := range cr.codecs {
+		if normalizeMediaType(codec.ContentType()) == mediaType {
+			return codec
+		}
+	}
+	return nil
+}
+
+// Accept builds an Accept header value listing the registered media types.
+func (cr *CodecRegistry) Accept() string {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	mediaTypes := make([]string, 0, len(cr.codecs)+1)
+	for _, codec := range cr.codecs {
+		mediaTypes = append(mediaTypes, normalizeMediaType(codec.ContentType()))
+	}
+	// Still accept anything else, at the lowest preference
+	mediaTypes = append(mediaTypes, "*/*;q=0.1")
+
+	return strings.Join(mediaTypes, ", ")
+}
+
+// Clone creates a copy of the registry.
+func (cr *CodecRegistry) Clone() *CodecRegistry {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	codecs := make([]contracts.Codec, len(cr.codecs))
+	copy(codecs, cr.codecs)
+	return &CodecRegistry{codecs: codecs}
+}
+
+// normalizeMediaType strips parameters and lowercases a Content-Type value.
+func normalizeMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// JSONCodec encodes and decodes application/json bodies.
+type JSONCodec struct{}
+
+// ContentType implements contracts.Codec.
+func (JSONCodec) ContentType() string { return ContentTypeJSON }
+
+// Encode implements contracts.Codec.
+func (JSONCodec) Encode(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Decode implements contracts.Codec.
+func (JSONCodec) Decode(data []byte, target interface{}) error { return json.Unmarshal(data, target) }
+
+// XMLCodec encodes and decodes application/xml bodies.
+type XMLCodec struct{}
+
+// ContentType implements contracts.Codec.
+func (XMLCodec) ContentType() string { return ContentTypeXML }
+
+// Encode implements contracts.Codec.
+func (XMLCodec) Encode(v interface{}) ([]byte, error) { return xml.Marshal(v) }
+
+// Decode implements contracts.Codec. Documents declaring another encoding in
+// their prolog are decoded to UTF-8.
+func (XMLCodec) Decode(data []byte, target interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	return decoder.Decode(target)
+}
+
+// FormCodec encodes and decodes application/x-www-form-urlencoded bodies.
+// Bodies may be url.Values, map[string]string, map[string][]string,
+// map[string]interface{} or an already encoded string or []byte.
+type FormCodec struct{}
+
+// ContentType implements contracts.Codec.
+func (FormCodec) ContentType() string { return ContentTypeForm }
+
+// Encode implements contracts.Codec.
+func (FormCodec) Encode(v interface{}) ([]byte, error) {
+	switch body := v.(type) {
+	case url.Values:
+		return []byte(body.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(body).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range body {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	case map[string]interface{}:
+		values := url.Values{}
+		for key, value := range body {
+			values.Set(key, fmt.Sprintf("%v", value))
+		}
+		return []byte(values.Encode()), nil
+	case string:
+		return []byte(body), nil
+	case []byte:
+		return body, nil
+	default:
+		return nil, fmt.Errorf("cannot form-encode %T", v)
+	}
+}
+
+// Decode implements contracts.Codec.
+// Targets may be *url.Values, *map[string]string or *map[string][]string.
+func (FormCodec) Decode(data []byte, target interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch t := target.(type) {
+	case *url.Values:
+		*t = values
+	case *map[string][]string:
+		*t = values
+	case *map[string]string:
+		*t = make(map[string]string, len(values))
+		for key := range values {
+			(*t)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("cannot form-decode into %T", target)
+	}
+	return nil
+}
+
+// TextCodec encodes and decodes text/plain bodies.
+// Many servers label JSON as text/plain, so targets other than *string,
+// *[]byte and encoding.TextUnmarshaler are decoded as JSON.
+type TextCodec struct{}
+
+// ContentType implements contracts.Codec.
+func (TextCodec) ContentType() string { return ContentTypeText }
+
+// Encode implements contracts.Codec.
+func (TextCodec) Encode(v interface{}) ([]byte, error) {
+	switch body := v.(type) {
+	case string:
+		return []byte(body), nil
+	case []byte:
+		return body, nil
+	case encoding.TextMarshaler:
+		return body.MarshalText()
+	case fmt.Stringer:
+		return []byte(body.String()), nil
+	default:
+		return nil, fmt.Errorf("cannot encode %T as text", v)
+	}
+}
+
+// Decode implements contracts.Codec.
+func (TextCodec) Decode(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(data)
+	case *[]byte:
+		*t = append((*t)[:0], data...)
+	case encoding.TextUnmarshaler:
+		return t.UnmarshalText(data)
+	default:
+		return json.Unmarshal(data, target)
+	}
+	return nil
+}
+
+// BytesCodec passes application/octet-stream bodies through unchanged.
+// Like text/plain, the type is a common default for JSON APIs, so targets
+// other than *[]byte and *string are decoded as JSON.
+type BytesCodec struct{}
+
+// ContentType implements contracts.Codec.
+func (BytesCodec) ContentType() string { return ContentTypeBytes }
+
+// Encode implements contracts.Codec.
+func (BytesCodec) Encode(v interface{}) ([]byte, error) {
+	switch body := v.(type) {
+	case []byte:
+		return body, nil
+	case string:
+		return []byte(body), nil
+	default:
+		return nil, fmt.Errorf("cannot encode %T as raw bytes", v)
+	}
+}
+
+// Decode implements contracts.Codec.
+func (BytesCodec) Decode(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *[]byte:
+		*t = append((*t)[:0], data...)
+	case *string:
+		*t = string(data)
+	default:
+		return json.Unmarshal(data, target)
+	}
+	return nil
+}
+
+// SetCodec registers a codec on the client, replacing any codec for the same media type.
+func (c *Client) SetCodec(codec contracts.Codec) *Client {
+	c.codecs.Register(codec)
+	return c
+}
+
+// encodeBody serializes a request body with the codec for the request Content-Type.
+// Bodies without a Content-Type are encoded as JSON.
+func (c *Client) encodeBody(body interface{}, contentType string) ([]byte, string, error) {
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	codec := c.codecs.Lookup(contentType)
+	if codec == nil {
+		return nil, "", fmt.Errorf("no codec registered for Content-Type %q", contentType)
+	}
+
+	data, err := codec.Encode(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+// decodeBody deserializes a response body with the codec for the response Content-Type.
+// Bodies with a missing or unknown Content-Type are decoded as JSON.
+func (c *Client) decodeBody(data []byte, contentType string, target interface{}) error {
+	codec := c.codecs.Lookup(contentType)
+	if codec == nil {
+		codec = c.codecs.Lookup(ContentTypeJSON)
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return codec.Decode(data, target)
+}
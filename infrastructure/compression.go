@@ -0,0 +1,230 @@
+package infrastructure
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fourth-ally/gofetch/domain/contracts"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings supported for request compression and response decompression.
+const (
+	EncodingGzip   = "gzip"
+	EncodingZstd   = "zstd"
+	EncodingBrotli = "br"
+)
+
+// SetCompressionOptions configures compression of request bodies.
+//
+// Example:
+//
+//	client.SetCompressionOptions(&models.CompressionOptions{
+//	    Encoding: infrastructure.EncodingZstd,
+//	    MinSize:  1024,
+//	})
+func (c *Client) SetCompressionOptions(options *models.CompressionOptions) *Client {
+	c.config.Compression = options
+	return c
+}
+
+// isSupportedEncoding reports whether a content coding can be encoded and decoded.
+func isSupportedEncoding(encoding string) bool {
+	switch encoding {
+	case EncodingGzip, EncodingZstd, EncodingBrotli:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestEncoding returns the content coding to apply to a request body of the
+// given length (-1 if unknown), or "" to send it as is. Bodies that already
+// have a Content-Encoding and parts of a larger upload are never compressed.
+func requestEncoding(options *models.CompressionOptions, headers http.Header, body interface{}, length int64) (string, error) {
+	if options == nil || options.Encoding == "" || headers.Get("Content-Encoding") != "" {
+		return "", nil
+	}
+	if _, ok := body.(uploadPart); ok {
+		return "", nil
+	}
+	if !isSupportedEncoding(options.Encoding) {
+		return "", fmt.Errorf("unsupported request compression %q", options.Encoding)
+	}
+	if length >= 0 && length < options.MinSize {
+		return "", nil
+	}
+	return options.Encoding, nil
+}
+
+// newEncoder creates a compressing writer for a content coding.
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// newDecoder creates a decompressing reader for a content coding.
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case EncodingGzip:
+		decoder, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder, nil
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case EncodingBrotli:
+		return brotli.NewReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// compressBytes compresses an in-memory request body.
+func compressBytes(data []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder, err := newEncoder(encoding, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := encoder.Write(data); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressStream compresses a streamed request body through a pipe.
+func compressStream(reader io.ReadCloser, encoding string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		encoder, err := newEncoder(encoding, pw)
+		if err == nil {
+			_, err = io.Copy(encoder, reader)
+			if closeErr := encoder.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		reader.Close()
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// decodeResponse replaces the body of a compressed response with a decoding
+// reader, the way the transport does for gzip when it negotiates compression.
+func decodeResponse(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if !isSupportedEncoding(encoding) || resp.Request.Method == http.MethodHead ||
+		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return
+	}
+
+	resp.Body = &decodedBody{
+		wire:     &progressReader{reader: resp.Body, total: resp.ContentLength},
+		body:     resp.Body,
+		encoding: encoding,
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody is a response body decompressed from its Content-Encoding.
+// It counts the bytes read from the wire as well as the decoded bytes, so
+// download progress stays meaningful for compressed responses.
+type decodedBody struct {
+	wire     *progressReader
+	body     io.Closer
+	encoding string
+	decoder  io.Reader
+	decoded  int64
+	progress contracts.ProgressCallback
+	transfer contracts.TransferProgressCallback
+}
+
+// Read implements io.Reader. The decoder is created on the first read, as
+// some codings read a header from the body.
+func (d *decodedBody) Read(p []byte) (int, error) {
+	if d.decoder == nil {
+		decoder, err := newDecoder(d.encoding, d.wire)
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode %s response: %w", d.encoding, err)
+		}
+		d.decoder = decoder
+	}
+
+	n, err := d.decoder.Read(p)
+	d.decoded += int64(n)
+
+	// Digests of the encoded bytes are checked when the wire reaches EOF,
+	// which a decoder may not read up to
+	if err == io.EOF && d.wire.verifier != nil {
+		if _, drainErr := io.Copy(io.Discard, d.wire); drainErr != nil {
+			err = drainErr
+		}
+	}
+
+	if d.progress != nil {
+		d.progress(d.wire.transferred, d.wire.total)
+	}
+	if d.transfer != nil {
+		d.transfer(d.wire.transferred, d.decoded, d.wire.total)
+	}
+
+	return n, err
+}
+
+// Close implements io.Closer.
+func (d *decodedBody) Close() error {
+	if closer, ok := d.decoder.(io.Closer); ok {
+		closer.Close()
+	}
+	return d.body.Close()
+}
+
+// trackDecoded reports download progress of a decoded body in wire bytes and
+// returns whether body is one. Other bodies are tracked by the caller.
+func (c *Client) trackDecoded(body io.Reader) bool {
+	decoded := decodedBodyOf(body)
+	if decoded != nil {
+		decoded.progress = c.downloadProgress
+		decoded.transfer = c.transferProgress
+	}
+	return decoded != nil
+}
+
+// decodedBodyOf returns the decoded body behind a response body, or nil if
+// the body was not decompressed by the client.
+func decodedBodyOf(body io.Reader) *decodedBody {
+	if stream, ok := body.(*streamReadCloser); ok {
+		body = stream.reader
+	}
+	decoded, _ := body.(*decodedBody)
+	return decoded
+}
@@ -0,0 +1,15 @@
+//go:build js && wasm
+// +build js,wasm
+
+package infrastructure
+
+import "net/http"
+
+// negotiateEncoding does nothing in the browser: Accept-Encoding is a
+// forbidden header there, and fetch negotiates the content codings itself.
+func negotiateEncoding(headers http.Header) {}
+
+// decodeNegotiated does nothing in the browser, where fetch has already
+// decoded the body. Decoding it again would corrupt br and zstd bodies, whose
+// Content-Encoding header is kept by the transport.
+func decodeNegotiated(req *http.Request, resp *http.Response) {}
@@ -0,0 +1,27 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package infrastructure
+
+import "net/http"
+
+// acceptEncoding is sent when a request does not set Accept-Encoding itself.
+// Responses are then decoded by the client instead of the transport.
+const acceptEncoding = "gzip, br, zstd"
+
+// negotiateEncoding advertises the supported content codings on a request
+// that does not set Accept-Encoding itself.
+func negotiateEncoding(headers http.Header) {
+	if headers.Get("Accept-Encoding") == "" {
+		headers.Set("Accept-Encoding", acceptEncoding)
+	}
+}
+
+// decodeNegotiated decodes a response to a request whose content codings
+// were advertised by negotiateEncoding. A caller that sets its own
+// Accept-Encoding gets the body as sent.
+func decodeNegotiated(req *http.Request, resp *http.Response) {
+	if req.Header.Get("Accept-Encoding") == acceptEncoding {
+		decodeResponse(resp)
+	}
+}
@@ -0,0 +1,572 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// CookieFileFormat is the file format of a persistent cookie jar.
+type CookieFileFormat string
+
+const (
+	// CookieFileJSON stores cookies as a JSON array with all their attributes.
+	CookieFileJSON CookieFileFormat = "json"
+	// CookieFileNetscape stores cookies in the Netscape cookies.txt format used by curl and wget.
+	CookieFileNetscape CookieFileFormat = "netscape"
+)
+
+// SetCookieJar sets the cookie jar used to store and send cookies, such as
+// a CookieJar from NewCookieJar or NewPersistentCookieJar. Any http.CookieJar
+// is accepted; nil disables cookies. Instances created with NewInstance share the jar.
+func (c *Client) SetCookieJar(jar http.CookieJar) *Client {
+	c.httpClient.Jar = jar
+	return c
+}
+
+// GetCookieJar returns the client's cookie jar, or nil if none is set.
+func (c *Client) GetCookieJar() http.CookieJar {
+	return c.httpClient.Jar
+}
+
+// Cookies returns the cookies the jar would send with a request to path,
+// which is resolved against the base URL.
+func (c *Client) Cookies(path string) ([]*http.Cookie, error) {
+	if c.httpClient.Jar == nil {
+		return nil, nil
+	}
+	u, err := resolveURL(c.config.BaseURL, path, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Jar.Cookies(u), nil
+}
+
+// ClearCookies removes all cookies from the client's jar if it supports it, as CookieJar does.
+func (c *Client) ClearCookies() *Client {
+	if jar, ok := c.httpClient.Jar.(interface{ Clear() }); ok {
+		jar.Clear()
+	}
+	return c
+}
+
+// CookieJar is an in-memory http.CookieJar following RFC 6265. It rejects
+// cookies set for public suffixes such as co.uk, and unlike
+// net/http/cookiejar its cookies can be listed, deleted and persisted to a file.
+// A CookieJar is safe for concurrent use.
+type CookieJar struct {
+	mu       sync.Mutex
+	entries  map[string]*cookieEntry
+	suffixes cookiejar.PublicSuffixList
+	filename string
+	format   CookieFileFormat
+	sequence uint64
+}
+
+// cookieEntry is a stored cookie.
+type cookieEntry struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	HostOnly bool      `json:"hostOnly"`
+	Secure   bool      `json:"secure"`
+	HttpOnly bool      `json:"httpOnly"`
+	SameSite string    `json:"sameSite,omitempty"`
+	Expires  time.Time `json:"expires,omitzero"`
+	Creation time.Time `json:"creation"`
+
+	// sequence orders cookies created at the same time
+	sequence uint64
+}
+
+// NewCookieJar creates an in-memory cookie jar that uses the public suffix list.
+func NewCookieJar() *CookieJar {
+	return &CookieJar{
+		entries:  make(map[string]*cookieEntry),
+		suffixes: publicsuffix.List,
+	}
+}
+
+// NewPersistentCookieJar creates a cookie jar backed by a file. Cookies are
+// loaded from the file if it exists and the file is rewritten whenever the
+// cookies change. Session cookies are saved as well, so a session survives a
+// restart. Automatic saves ignore write errors; call Save to check them.
+func NewPersistentCookieJar(filename string, format CookieFileFormat) (*CookieJar, error) {
+	if format != CookieFileJSON && format != CookieFileNetscape {
+		return nil, fmt.Errorf("unsupported cookie file format %q", format)
+	}
+
+	jar := NewCookieJar()
+	jar.filename = filename
+	jar.format = format
+
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return jar, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	var entries []*cookieEntry
+	if format == CookieFileJSON {
+		if len(bytes.TrimSpace(data)) > 0 {
+			err = json.Unmarshal(data, &entries)
+		}
+	} else {
+		entries, err = parseNetscapeCookies(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookie file: %w", err)
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.expired(now) {
+			continue
+		}
+		if entry.Creation.IsZero() {
+			entry.Creation = now
+		}
+		jar.sequence++
+		entry.sequence = jar.sequence
+		jar.entries[entry.key()] = entry
+	}
+	return jar, nil
+}
+
+// SetCookies implements http.CookieJar.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+	host := canonicalHost(u.Host)
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	changed := false
+	for _, cookie := range cookies {
+		entry, remove, ok := j.newEntry(cookie, host, u.Path, now)
+		if !ok {
+			continue
+		}
+
+		key := entry.key()
+		if remove {
+			if _, found := j.entries[key]; found {
+				delete(j.entries, key)
+				changed = true
+			}
+			continue
+		}
+
+		if old, found := j.entries[key]; found {
+			entry.Creation, entry.sequence = old.Creation, old.sequence
+		} else {
+			j.sequence++
+			entry.sequence = j.sequence
+		}
+		j.entries[key] = entry
+		changed = true
+	}
+
+	if changed {
+		j.saveLocked()
+	}
+}
+
+// Cookies implements http.CookieJar. Cookies with longer paths are listed
+// first, then cookies created earlier.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	host := canonicalHost(u.Host)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https"
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var matches []*cookieEntry
+	expired := false
+	for key, entry := range j.entries {
+		if entry.expired(now) {
+			delete(j.entries, key)
+			expired = true
+			continue
+		}
+		if entry.Secure && !secure {
+			continue
+		}
+		if !entry.domainMatch(host) || !pathMatch(path, entry.Path) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+	if expired {
+		j.saveLocked()
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if len(matches[a].Path) != len(matches[b].Path) {
+			return len(matches[a].Path) > len(matches[b].Path)
+		}
+		return matches[a].sequence < matches[b].sequence
+	})
+
+	cookies := make([]*http.Cookie, len(matches))
+	for i, entry := range matches {
+		cookies[i] = &http.Cookie{Name: entry.Name, Value: entry.Value}
+	}
+	return cookies
+}
+
+// All returns every unexpired cookie in the jar with its attributes, sorted
+// by domain, path and name. Session cookies have a zero Expires.
+func (j *CookieJar) All() []*http.Cookie {
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]*cookieEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		if !entry.expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].key() < entries[b].key()
+	})
+
+	cookies := make([]*http.Cookie, len(entries))
+	for i, entry := range entries {
+		cookies[i] = entry.cookie()
+	}
+	return cookies
+}
+
+// Delete removes the cookie with the given domain, path and name.
+// It reports whether the cookie was found.
+func (j *CookieJar) Delete(domain, path, name string) bool {
+	key := strings.ToLower(strings.TrimPrefix(domain, ".")) + ";" + path + ";" + name
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, found := j.entries[key]; !found {
+		return false
+	}
+	delete(j.entries, key)
+	j.saveLocked()
+	return true
+}
+
+// Clear removes all cookies.
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = make(map[string]*cookieEntry)
+	j.saveLocked()
+}
+
+// Save writes the cookies to the jar's file. It does nothing for in-memory jars.
+func (j *CookieJar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.saveLocked()
+}
+
+// saveLocked writes the cookies to the file of a persistent jar, replacing it atomically.
+func (j *CookieJar) saveLocked() error {
+	if j.filename == "" {
+		return nil
+	}
+
+	entries := make([]*cookieEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].key() < entries[b].key()
+	})
+
+	var data []byte
+	if j.format == CookieFileJSON {
+		var err error
+		if data, err = json.MarshalIndent(entries, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		data = formatNetscapeCookies(entries)
+	}
+
+	tmp := j.filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	if err := os.Rename(tmp, j.filename); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	return nil
+}
+
+// newEntry creates the entry for a cookie received from host. It reports
+// whether the cookie removes an existing one, and false if it is rejected.
+func (j *CookieJar) newEntry(cookie *http.Cookie, host, requestPath string, now time.Time) (*cookieEntry, bool, bool) {
+	if cookie.Name == "" {
+		return nil, false, false
+	}
+
+	domain, hostOnly, ok := j.cookieDomain(host, cookie.Domain)
+	if !ok {
+		return nil, false, false
+	}
+
+	path := cookie.Path
+	if path == "" || path[0] != '/' {
+		path = defaultCookiePath(requestPath)
+	}
+
+	entry := &cookieEntry{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   domain,
+		Path:     path,
+		HostOnly: hostOnly,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		SameSite: sameSiteName(cookie.SameSite),
+		Creation: now,
+	}
+
+	// Max-Age takes precedence over Expires
+	switch {
+	case cookie.MaxAge < 0:
+		return entry, true, true
+	case cookie.MaxAge > 0:
+		entry.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	case !cookie.Expires.IsZero():
+		if !cookie.Expires.After(now) {
+			return entry, true, true
+		}
+		entry.Expires = cookie.Expires
+	}
+	return entry, false, true
+}
+
+// cookieDomain returns the domain a cookie is stored for and whether it is
+// host-only, following RFC 6265 section 5.3. It reports false if the Domain
+// attribute does not cover host or is a public suffix.
+func (j *CookieJar) cookieDomain(host, domain string) (string, bool, bool) {
+	if domain == "" {
+		return host, true, true
+	}
+
+	if net.ParseIP(host) != nil {
+		// IP addresses only accept cookies without a domain or for themselves
+		return host, true, host == domain
+	}
+
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || strings.HasSuffix(domain, ".") {
+		return "", false, false
+	}
+
+	if j.suffixes != nil && j.suffixes.PublicSuffix(domain) == domain {
+		// A public suffix is only accepted as a host-only cookie of that very host
+		return host, true, host == domain
+	}
+
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+// key identifies an entry by domain, path and name.
+func (e *cookieEntry) key() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+// expired reports whether a persistent cookie has expired.
+func (e *cookieEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !e.Expires.After(now)
+}
+
+// domainMatch reports whether the cookie is sent to host.
+func (e *cookieEntry) domainMatch(host string) bool {
+	if e.HostOnly {
+		return host == e.Domain
+	}
+	return host == e.Domain || strings.HasSuffix(host, "."+e.Domain)
+}
+
+// cookie converts the entry into an http.Cookie with all attributes.
+func (e *cookieEntry) cookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     e.Name,
+		Value:    e.Value,
+		Domain:   e.Domain,
+		Path:     e.Path,
+		Expires:  e.Expires,
+		Secure:   e.Secure,
+		HttpOnly: e.HttpOnly,
+	}
+	switch e.SameSite {
+	case "Lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "Strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "None":
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+// sameSiteName returns the attribute value of a SameSite mode.
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// pathMatch reports whether requestPath path-matches cookiePath (RFC 6265 section 5.1.4).
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath returns the default path of a cookie (RFC 6265 section 5.1.4).
+func defaultCookiePath(requestPath string) string {
+	if requestPath == "" || requestPath[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(requestPath, "/")
+	if i == 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+// canonicalHost lower-cases a host and strips its port.
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// netscapeHttpOnlyPrefix marks HttpOnly cookies in cookies.txt files, as curl does.
+const netscapeHttpOnlyPrefix = "#HttpOnly_"
+
+// formatNetscapeCookies writes entries in the Netscape cookies.txt format.
+// Session cookies are written with an expiry of 0.
+func formatNetscapeCookies(entries []*cookieEntry) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Netscape HTTP Cookie File\n\n")
+
+	for _, entry := range entries {
+		domain, subdomains := entry.Domain, "FALSE"
+		if !entry.HostOnly {
+			domain, subdomains = "."+domain, "TRUE"
+		}
+		if entry.HttpOnly {
+			domain = netscapeHttpOnlyPrefix + domain
+		}
+
+		var expires int64
+		if !entry.Expires.IsZero() {
+			expires = entry.Expires.Unix()
+		}
+
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, subdomains, entry.Path, netscapeBool(entry.Secure), expires, entry.Name, entry.Value)
+	}
+	return b.Bytes()
+}
+
+// parseNetscapeCookies reads entries from the Netscape cookies.txt format.
+func parseNetscapeCookies(data []byte) ([]*cookieEntry, error) {
+	var entries []*cookieEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(text, netscapeHttpOnlyPrefix)
+		if httpOnly {
+			text = strings.TrimPrefix(text, netscapeHttpOnlyPrefix)
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", line, fields[4])
+		}
+
+		entry := &cookieEntry{
+			Domain:   strings.ToLower(strings.TrimPrefix(fields[0], ".")),
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			entry.Expires = time.Unix(expires, 0)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// netscapeBool formats a flag of the cookies.txt format.
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,277 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SetDigestAuth authenticates requests with HTTP Digest authentication
+// (RFC 7616) using the MD5, SHA-256 and their -sess algorithms. A request
+// answered with a Digest challenge is sent once more with credentials, and the
+// challenge is cached so later requests to the same origin authenticate up
+// front with an increasing nonce count. The credentials are computed after the
+// request interceptors, so they cover the final method and URI. Requests with
+// an Authorization header are left alone. An empty username disables Digest
+// authentication.
+//
+// Example:
+//
+//	client.SetDigestAuth("admin", password)
+func (c *Client) SetDigestAuth(username, password string) *Client {
+	if username == "" {
+		c.digest = nil
+		return c
+	}
+	c.digest = &digestAuth{username: username, password: password}
+	return c
+}
+
+// digestAuth holds the credentials and the last challenge of a client.
+type digestAuth struct {
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+}
+
+// digestChallenge is a Digest challenge accepted for an origin.
+type digestChallenge struct {
+	origin    string
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+	newHash   func() hash.Hash
+	sess      bool
+	count     uint32
+
+	// cnonce and ha1 are fixed for the nonce, so the session key of the
+	// -sess algorithms is computed once (RFC 7616 section 3.4.2)
+	cnonce string
+	ha1    string
+}
+
+// digestAuthAlgorithms maps the supported algorithms to their hash functions.
+var digestAuthAlgorithms = map[string]func() hash.Hash{
+	"MD5":     md5.New,
+	"SHA-256": sha256.New,
+}
+
+// authorize sets the Authorization header of a request from the cached
+// challenge, if there is one for the request's origin.
+func (d *digestAuth) authorize(req *http.Request) error {
+	d.mu.Lock()
+	challenge := d.challenge
+	if challenge == nil || !sameOrigin(challenge.origin, req.URL) {
+		d.mu.Unlock()
+		return nil
+	}
+	challenge.count++
+	count := challenge.count
+	d.mu.Unlock()
+
+	credentials, err := d.credentials(challenge, count, req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", credentials)
+	return nil
+}
+
+// resend answers a Digest challenge by sending the request once more with
+// credentials. The original response is returned if it is not a supported
+// challenge or the body cannot be replayed.
+func (d *digestAuth) resend(ctx context.Context, req *http.Request, resp *http.Response, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if resp.StatusCode != http.StatusUnauthorized || !canReplay(req) {
+		return resp, nil
+	}
+	challenge := selectDigestChallenge(parseChallenges(resp.Header.Values("WWW-Authenticate")))
+	if challenge == nil {
+		return resp, nil
+	}
+	challenge.origin = originOf(req.URL.String())
+
+	cnonce, err := digestNonce()
+	if err != nil {
+		return resp, nil
+	}
+	challenge.cnonce = cnonce
+	challenge.ha1 = digestHash(challenge.newHash, d.username+":"+challenge.realm+":"+d.password)
+	if challenge.sess {
+		challenge.ha1 = digestHash(challenge.newHash, challenge.ha1+":"+challenge.nonce+":"+cnonce)
+	}
+
+	d.mu.Lock()
+	d.challenge = challenge
+	d.mu.Unlock()
+
+	retry, err := replayRequest(ctx, req)
+	if err != nil {
+		return resp, nil
+	}
+	if err := d.authorize(retry); err != nil {
+		closeRequestBody(retry)
+		return resp, nil
+	}
+
+	discardResponse(resp)
+	return send(retry)
+}
+
+// credentials computes the Authorization header value for a request (RFC 7616 section 3.4).
+func (d *digestAuth) credentials(challenge *digestChallenge, count uint32, req *http.Request) (string, error) {
+	h := func(data string) string {
+		return digestHash(challenge.newHash, data)
+	}
+
+	cnonce := challenge.cnonce
+	nc := fmt.Sprintf("%08x", count)
+	uri := req.URL.RequestURI()
+
+	a2 := req.Method + ":" + uri
+	if challenge.qop == "auth-int" {
+		bodyHash, err := digestBodyHash(req, challenge.newHash)
+		if err != nil {
+			return "", err
+		}
+		a2 += ":" + bodyHash
+	}
+
+	var response string
+	if challenge.qop == "" {
+		// RFC 2069 compatibility
+		response = h(challenge.ha1 + ":" + challenge.nonce + ":" + h(a2))
+	} else {
+		response = h(challenge.ha1 + ":" + challenge.nonce + ":" + nc + ":" + cnonce + ":" + challenge.qop + ":" + h(a2))
+	}
+
+	username := d.username
+	if challenge.userhash {
+		username = h(d.username + ":" + challenge.realm)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username=%s, realm=%s, nonce=%s, uri=%s, algorithm=%s, response=%s`,
+		quoteParam(username), quoteParam(challenge.realm), quoteParam(challenge.nonce), quoteParam(uri), challenge.algorithm, quoteParam(response))
+	if challenge.qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce=%s`, challenge.qop, nc, quoteParam(cnonce))
+	}
+	if challenge.opaque != "" {
+		fmt.Fprintf(&b, `, opaque=%s`, quoteParam(challenge.opaque))
+	}
+	if challenge.userhash {
+		b.WriteString(", userhash=true")
+	}
+	return b.String(), nil
+}
+
+// selectDigestChallenge picks the strongest supported Digest challenge,
+// preferring SHA-256 over MD5.
+func selectDigestChallenge(challenges []authChallenge) *digestChallenge {
+	var selected *digestChallenge
+	for _, challenge := range challenges {
+		if challenge.scheme != "digest" || challenge.params["nonce"] == "" {
+			continue
+		}
+
+		// The algorithm is echoed as sent but matched case-insensitively
+		algorithm := challenge.params["algorithm"]
+		if algorithm == "" {
+			algorithm = "MD5"
+		}
+		name := strings.ToUpper(algorithm)
+		newHash, ok := digestAuthAlgorithms[strings.TrimSuffix(name, "-SESS")]
+		if !ok {
+			continue
+		}
+
+		qop, ok := selectDigestQop(challenge.params["qop"])
+		if !ok {
+			continue
+		}
+
+		candidate := &digestChallenge{
+			realm:     challenge.params["realm"],
+			nonce:     challenge.params["nonce"],
+			opaque:    challenge.params["opaque"],
+			algorithm: algorithm,
+			qop:       qop,
+			userhash:  strings.EqualFold(challenge.params["userhash"], "true"),
+			newHash:   newHash,
+			sess:      strings.HasSuffix(name, "-SESS"),
+		}
+		if selected == nil || (strings.HasPrefix(name, "SHA-256") && !strings.HasPrefix(strings.ToUpper(selected.algorithm), "SHA-256")) {
+			selected = candidate
+		}
+	}
+	return selected
+}
+
+// selectDigestQop picks auth over auth-int from the offered qop values. An
+// absent qop selects RFC 2069 compatibility.
+func selectDigestQop(offered string) (string, bool) {
+	if offered == "" {
+		return "", true
+	}
+	var authInt bool
+	for _, qop := range strings.Split(offered, ",") {
+		switch strings.ToLower(strings.TrimSpace(qop)) {
+		case "auth":
+			return "auth", true
+		case "auth-int":
+			authInt = true
+		}
+	}
+	return "auth-int", authInt
+}
+
+// digestBodyHash hashes the request body for the auth-int quality of protection.
+func digestBodyHash(req *http.Request, newHash func() hash.Hash) (string, error) {
+	hasher := newHash()
+	if req.GetBody != nil {
+		body, err := openPayload(req)
+		if err != nil {
+			return "", fmt.Errorf("digest auth: failed to read body: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(hasher, body); err != nil {
+			return "", fmt.Errorf("digest auth: failed to read body: %w", err)
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		return "", fmt.Errorf("digest auth: auth-int requires a replayable body")
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// digestHash returns the hex digest of data.
+func digestHash(newHash func() hash.Hash, data string) string {
+	hasher := newHash()
+	io.WriteString(hasher, data)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// digestNonce returns a random client nonce.
+func digestNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("digest auth: failed to generate cnonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// quoteParam formats a quoted-string auth-param value.
+func quoteParam(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
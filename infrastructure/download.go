@@ -0,0 +1,279 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// Download streams a GET response straight into dstFile, see Request.Download.
+func (c *Client) Download(ctx context.Context, path string, dstFile string, configs ...*models.Config) (*models.DownloadResult, error) {
+	return c.newRequest(http.MethodGet, path, nil, nil, nil, configs).Download(ctx, dstFile)
+}
+
+// Download executes the request and streams the body into dstFile.
+//
+// Data is written to dstFile+".part" and renamed into place once complete.
+// The response's strong ETag or Last-Modified is stored next to it in
+// dstFile+".part.json", so an interrupted transfer, whether retried by the
+// RetryManager or started again by a later call, resumes with Range and
+// If-Range instead of starting over. If the server ignores the range or the
+// resource changed, the download restarts from the beginning. Download
+// progress reports absolute offsets within the file. With integrity options
+// set, the whole file is verified, and a mismatch discards it before retrying.
+func (r *Request) Download(ctx context.Context, dstFile string) (*models.DownloadResult, error) {
+	c := r.client
+	config := c.resolveConfig(r.config)
+
+	fullURL, err := c.buildURL(config, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL: %w", err)
+	}
+
+	d := &downloader{
+		client:    c,
+		request:   r,
+		url:       fullURL,
+		partFile:  dstFile + ".part",
+		stateFile: dstFile + ".part.json",
+	}
+	d.loadState()
+
+	result, err := executeWithRetry(ctx, c, r, func(config *models.Config) (*models.DownloadResult, int, error) {
+		return d.attempt(ctx, config)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(d.partFile, dstFile); err != nil {
+		return nil, fmt.Errorf("failed to move download into place: %w", err)
+	}
+	os.Remove(d.stateFile)
+
+	result.Path = dstFile
+	result.Resumed = d.resumed
+	return result, nil
+}
+
+// downloadState is persisted next to a partial download to allow resuming it.
+type downloadState struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validator returns the If-Range value for the state, or "" if it cannot be resumed.
+func (s *downloadState) validator() string {
+	if s.ETag != "" {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+// downloader carries the state of one Download call across attempts.
+type downloader struct {
+	client    *Client
+	request   *Request
+	url       string
+	partFile  string
+	stateFile string
+	state     downloadState
+	resumed   int
+}
+
+// loadState reads the stored validators of a previous partial download of the same URL.
+func (d *downloader) loadState() {
+	d.state = downloadState{URL: d.url}
+
+	data, err := os.ReadFile(d.stateFile)
+	if err != nil {
+		return
+	}
+
+	var stored downloadState
+	if json.Unmarshal(data, &stored) == nil && stored.URL == d.url {
+		d.state = stored
+	}
+}
+
+// saveState stores the validators of the current response.
+func (d *downloader) saveState(headers http.Header) error {
+	d.state.ETag = ""
+	if etag := headers.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		// Weak ETags cannot be used with If-Range
+		d.state.ETag = etag
+	}
+	d.state.LastModified = headers.Get("Last-Modified")
+
+	data, err := json.Marshal(d.state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.stateFile, data, 0o644)
+}
+
+// attempt requests the remaining bytes and appends them to the partial file.
+func (d *downloader) attempt(ctx context.Context, config *models.Config) (*models.DownloadResult, int, error) {
+	c := d.client
+
+	var offset int64
+	if info, err := os.Stat(d.partFile); err == nil && d.state.validator() != "" {
+		offset = info.Size()
+	}
+
+	// Transparent compression would make byte ranges meaningless
+	headers := map[string]string{"Accept-Encoding": "identity"}
+	if offset > 0 {
+		headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+		headers["If-Range"] = d.state.validator()
+	}
+
+	resp, err := c.executeStream(ctx, d.request, config.Merge(&models.Config{Headers: headers}))
+	if err != nil {
+		if httpErr, ok := err.(*errors.HTTPError); ok && httpErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			return d.rangeNotSatisfiable(httpErr, offset)
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Anything but a matching partial response means starting over
+	total := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		start, size, ok := parseContentRange(resp.Headers.Get("Content-Range"))
+		if !ok || start != offset {
+			d.reset()
+			return nil, resp.StatusCode, fmt.Errorf("download: unexpected Content-Range %q for offset %d", resp.Headers.Get("Content-Range"), offset)
+		}
+		total = size
+		d.resumed++
+	} else {
+		offset = 0
+	}
+
+	if err := d.saveState(resp.Headers); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to save download state: %w", err)
+	}
+
+	// The representation digests cover the whole file, including a resumed prefix
+	verifier, err := newBodyVerifier(config.Integrity, resp.Headers, verifyContent|verifyRepresentation, resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	file, err := os.OpenFile(d.partFile, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to open download file: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Truncate(offset); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to prepare download file: %w", err)
+	}
+	if verifier != nil && offset > 0 {
+		if err := verifier.prime(file); err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("failed to read partial download: %w", err)
+		}
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to prepare download file: %w", err)
+	}
+
+	c.trackDownload(resp, offset, total, verifier)
+	written, err := io.Copy(file, resp.Body)
+	if integrityErr, ok := err.(*errors.IntegrityError); ok {
+		// Resuming would keep the corrupted bytes
+		file.Close()
+		d.reset()
+		return nil, resp.StatusCode, integrityErr
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("download interrupted at offset %d: %w", offset+written, err)
+	}
+
+	size := offset + written
+	if total >= 0 && size != total {
+		return nil, 0, fmt.Errorf("download interrupted at offset %d of %d: %w", size, total, io.ErrUnexpectedEOF)
+	}
+
+	return &models.DownloadResult{
+		Size:       size,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Headers,
+	}, resp.StatusCode, nil
+}
+
+// rangeNotSatisfiable handles a 416 response to a resume request. The partial
+// file is complete when its size equals the representation length; otherwise
+// it is discarded so the next attempt starts over.
+func (d *downloader) rangeNotSatisfiable(httpErr *errors.HTTPError, offset int64) (*models.DownloadResult, int, error) {
+	if size, ok := parseUnsatisfiedRange(httpErr.Headers.Get("Content-Range")); ok && size == offset && offset > 0 {
+		return &models.DownloadResult{
+			Size:       offset,
+			StatusCode: httpErr.StatusCode,
+			Headers:    httpErr.Headers,
+		}, httpErr.StatusCode, nil
+	}
+
+	d.reset()
+	return nil, httpErr.StatusCode, httpErr
+}
+
+// reset discards the partial download.
+func (d *downloader) reset() {
+	os.Remove(d.partFile)
+	os.Remove(d.stateFile)
+	d.state = downloadState{URL: d.url}
+}
+
+// parseContentRange parses "bytes start-end/size" and returns the start offset
+// and the complete length, which is -1 when the server sent "*".
+func parseContentRange(value string) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+
+	rangePart, sizePart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, false
+	}
+
+	startPart, _, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(strings.TrimSpace(startPart), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if sizePart == "*" {
+		return start, -1, true
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(sizePart), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// parseUnsatisfiedRange parses the "bytes */size" Content-Range of a 416 response.
+func parseUnsatisfiedRange(value string) (int64, bool) {
+	sizePart, ok := strings.CutPrefix(value, "bytes */")
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(sizePart), 10, 64)
+	return size, err == nil
+}
@@ -0,0 +1,573 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// defaultSignedComponents are covered when no components are configured.
+var defaultSignedComponents = []string{"@method", "@target-uri", "content-digest"}
+
+// SetMessageSignature signs requests with HTTP Message Signatures (RFC 9421).
+// Like SigV4, signing is the last step before a request is sent, so the
+// signature covers the headers added by interceptors and the encoded body.
+// Nil disables signing.
+//
+// Example:
+//
+//	client.SetMessageSignature(&models.MessageSignatureOptions{
+//	    KeyID:      "partner-2024",
+//	    Key:        ed25519PrivateKey,
+//	    Components: []string{"@method", "@target-uri", "content-digest", "content-type"},
+//	})
+func (c *Client) SetMessageSignature(options *models.MessageSignatureOptions) *Client {
+	if options == nil {
+		c.messageSigner = nil
+		return c
+	}
+	c.messageSigner = NewMessageSigner(options)
+	return c
+}
+
+// SetSignatureVerification verifies HTTP Message Signatures (RFC 9421) of
+// accepted responses. Responses without a valid signature fail with an
+// errors.SignatureError before their body is read. If the signature covers
+// Content-Digest, the body is checked against it and fails with an
+// errors.IntegrityError on mismatch, before it reaches the data transformer.
+// Nil disables verification.
+func (c *Client) SetSignatureVerification(options *models.SignatureVerifyOptions) *Client {
+	if options == nil {
+		c.messageVerifier = nil
+		return c
+	}
+	c.messageVerifier = NewMessageVerifier(options)
+	return c
+}
+
+// MessageSigner signs HTTP requests with HTTP Message Signatures (RFC 9421).
+type MessageSigner struct {
+	options models.MessageSignatureOptions
+}
+
+// NewMessageSigner creates a signer with the given key and covered components.
+func NewMessageSigner(options *models.MessageSignatureOptions) *MessageSigner {
+	return &MessageSigner{options: *options}
+}
+
+// Sign sets the Signature-Input and Signature headers of a request, adding a
+// Content-Digest header first if it is covered and the request has a body.
+func (s *MessageSigner) Sign(req *http.Request, created time.Time) error {
+	names := s.options.Components
+	if len(names) == 0 {
+		names = defaultSignedComponents
+	}
+
+	components := make([]componentID, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if name == "content-digest" && req.Header.Get("Content-Digest") == "" {
+			digest, err := contentDigest(req)
+			if err != nil {
+				return err
+			}
+			if digest == "" {
+				continue
+			}
+			req.Header.Set("Content-Digest", digest)
+		}
+		components = append(components, componentID{name: name})
+	}
+
+	params := s.signatureParams(components, created)
+	base, err := signatureBase(components, params, req, nil)
+	if err != nil {
+		return fmt.Errorf("message signature: %w", err)
+	}
+	signature, err := signMessage(s.options.Key, []byte(base))
+	if err != nil {
+		return err
+	}
+
+	label := s.options.Label
+	if label == "" {
+		label = "sig1"
+	}
+	req.Header.Set("Signature-Input", label+"="+params)
+	req.Header.Set("Signature", label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return nil
+}
+
+// signatureParams serializes the covered components and signature parameters.
+func (s *MessageSigner) signatureParams(components []componentID, created time.Time) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, component := range components {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(component.String())
+	}
+	b.WriteString(");created=" + strconv.FormatInt(created.Unix(), 10))
+	if s.options.Expires > 0 {
+		b.WriteString(";expires=" + strconv.FormatInt(created.Add(s.options.Expires).Unix(), 10))
+	}
+	if s.options.KeyID != "" {
+		b.WriteString(";keyid=" + quoteParam(s.options.KeyID))
+	}
+	if s.options.Tag != "" {
+		b.WriteString(";tag=" + quoteParam(s.options.Tag))
+	}
+	return b.String()
+}
+
+// MessageVerifier verifies HTTP Message Signatures (RFC 9421) of responses.
+type MessageVerifier struct {
+	options models.SignatureVerifyOptions
+}
+
+// NewMessageVerifier creates a verifier with the given keys and requirements.
+func NewMessageVerifier(options *models.SignatureVerifyOptions) *MessageVerifier {
+	return &MessageVerifier{options: *options}
+}
+
+// Verify checks that a response has a valid signature from a known key that
+// covers the required components, returning an errors.SignatureError if not.
+// Components with the req parameter are taken from resp.Request. If the
+// signature covers Content-Digest, the body is wrapped so that reading it to
+// the end fails with an errors.IntegrityError when it does not match.
+func (v *MessageVerifier) Verify(resp *http.Response) error {
+	inputs := splitDictionary(resp.Header.Values("Signature-Input"))
+	signatures := splitDictionary(resp.Header.Values("Signature"))
+
+	labels := make([]string, 0, len(inputs))
+	for label := range inputs {
+		if v.options.Label == "" || label == v.options.Label {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return &errors.SignatureError{Label: v.options.Label, Reason: "response is not signed"}
+	}
+	sort.Strings(labels)
+
+	var firstErr error
+	for _, label := range labels {
+		input, err := v.verifySignature(label, inputs[label], signatures[label], resp)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if input.covers("content-digest") {
+			return verifyContentDigest(resp, label)
+		}
+		return nil
+	}
+	return firstErr
+}
+
+// verifySignature verifies a single signature of a response.
+func (v *MessageVerifier) verifySignature(label, rawInput, rawSignature string, resp *http.Response) (*signatureInput, error) {
+	input, err := parseSignatureInput(rawInput)
+	if err != nil {
+		return nil, &errors.SignatureError{Label: label, Reason: err.Error()}
+	}
+	keyID := input.params["keyid"]
+	fail := func(format string, args ...interface{}) error {
+		return &errors.SignatureError{Label: label, KeyID: keyID, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if len(rawSignature) < 2 || rawSignature[0] != ':' || rawSignature[len(rawSignature)-1] != ':' {
+		return nil, fail("missing or malformed Signature field")
+	}
+	signature, err := base64.StdEncoding.DecodeString(rawSignature[1 : len(rawSignature)-1])
+	if err != nil {
+		return nil, fail("malformed signature: %v", err)
+	}
+
+	key, ok := v.options.Keys[keyID]
+	if !ok {
+		return nil, fail("unknown key ID %q", keyID)
+	}
+
+	required := v.options.RequiredComponents
+	if len(required) == 0 {
+		required = []string{"@status"}
+	}
+	for _, name := range required {
+		if !input.covers(strings.ToLower(name)) {
+			return nil, fail("%q is not covered", name)
+		}
+	}
+
+	now := time.Now()
+	if expires, ok := input.params["expires"]; ok {
+		if seconds, err := strconv.ParseInt(expires, 10, 64); err != nil || now.After(time.Unix(seconds, 0)) {
+			return nil, fail("signature has expired")
+		}
+	}
+	if v.options.MaxAge > 0 {
+		seconds, err := strconv.ParseInt(input.params["created"], 10, 64)
+		if err != nil {
+			return nil, fail("signature has no creation time")
+		}
+		if now.Sub(time.Unix(seconds, 0)) > v.options.MaxAge {
+			return nil, fail("signature is older than %s", v.options.MaxAge)
+		}
+	}
+
+	base, err := signatureBase(input.components, rawInput, resp.Request, resp)
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	if err := verifyMessage(key, input.params["alg"], []byte(base), signature); err != nil {
+		return nil, fail("%v", err)
+	}
+	return input, nil
+}
+
+// componentID identifies a covered component, e.g. "@method";req.
+type componentID struct {
+	name string
+	// req takes the component from the request of a response.
+	req bool
+}
+
+// String serializes the component identifier.
+func (c componentID) String() string {
+	if c.req {
+		return `"` + c.name + `";req`
+	}
+	return `"` + c.name + `"`
+}
+
+// signatureInput is a parsed Signature-Input member.
+type signatureInput struct {
+	components []componentID
+	params     map[string]string
+}
+
+// covers reports whether a component is covered, from the message itself.
+func (s *signatureInput) covers(name string) bool {
+	for _, component := range s.components {
+		if component.name == name && !component.req {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSignatureInput parses an inner list of component identifiers with
+// its signature parameters, e.g. ("@method" "@path");created=1618884473;keyid="k".
+func parseSignatureInput(raw string) (*signatureInput, error) {
+	p := &headerParser{s: raw}
+	if !p.consume('(') {
+		return nil, fmt.Errorf("malformed Signature-Input")
+	}
+
+	input := &signatureInput{params: make(map[string]string)}
+	for {
+		p.skip(" ")
+		if p.consume(')') {
+			break
+		}
+		if p.peek() != '"' {
+			return nil, fmt.Errorf("malformed Signature-Input")
+		}
+		component := componentID{name: p.quoted()}
+		for p.consume(';') {
+			switch name := p.token(); name {
+			case "req":
+				component.req = true
+				if p.consume('=') {
+					p.token()
+				}
+			default:
+				return nil, fmt.Errorf("unsupported component parameter %q", name)
+			}
+		}
+		input.components = append(input.components, component)
+	}
+
+	for p.consume(';') {
+		name := p.token()
+		value := "?1"
+		if p.consume('=') {
+			if p.peek() == '"' {
+				value = p.quoted()
+			} else {
+				value = p.token()
+			}
+		}
+		input.params[name] = value
+	}
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("malformed Signature-Input")
+	}
+	return input, nil
+}
+
+// splitDictionary splits structured dictionary fields (RFC 8941) such as
+// Signature-Input into raw member values by key.
+func splitDictionary(values []string) map[string]string {
+	members := make(map[string]string)
+	for _, value := range values {
+		start, quoted := 0, false
+		for i := 0; i <= len(value); i++ {
+			if i < len(value) {
+				switch c := value[i]; {
+				case quoted && c == '\\':
+					i++
+					continue
+				case c == '"':
+					quoted = !quoted
+					continue
+				case quoted || c != ',':
+					continue
+				}
+			}
+
+			if key, raw, ok := strings.Cut(strings.TrimSpace(value[start:i]), "="); ok {
+				members[strings.TrimSpace(key)] = strings.TrimSpace(raw)
+			}
+			start = i + 1
+		}
+	}
+	return members
+}
+
+// signatureBase builds the signature base (RFC 9421 section 2.5) of a request,
+// or of a response if resp is not nil.
+func signatureBase(components []componentID, params string, req *http.Request, resp *http.Response) (string, error) {
+	var b strings.Builder
+	for _, component := range components {
+		value, err := componentValue(component, req, resp)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(component.String() + ": " + value + "\n")
+	}
+	b.WriteString(`"@signature-params": ` + params)
+	return b.String(), nil
+}
+
+// componentValue returns the value of a covered component.
+func componentValue(component componentID, req *http.Request, resp *http.Response) (string, error) {
+	fromResponse := resp != nil && !component.req
+	if component.req && resp == nil {
+		return "", fmt.Errorf("%s: req parameter on a request", component)
+	}
+
+	if !strings.HasPrefix(component.name, "@") {
+		var header http.Header
+		switch {
+		case fromResponse:
+			header = resp.Header
+		case req != nil:
+			header = req.Header
+		default:
+			return "", fmt.Errorf("%s: no request", component)
+		}
+		values := header.Values(component.name)
+		if len(values) == 0 {
+			return "", fmt.Errorf("%s is not present", component)
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.TrimSpace(value)
+		}
+		return strings.Join(trimmed, ", "), nil
+	}
+
+	if component.name == "@status" {
+		if !fromResponse {
+			return "", fmt.Errorf("%s is a response component", component)
+		}
+		return strconv.Itoa(resp.StatusCode), nil
+	}
+	if fromResponse {
+		return "", fmt.Errorf("%s is a request component", component)
+	}
+	if req == nil {
+		return "", fmt.Errorf("%s: no request", component)
+	}
+
+	switch component.name {
+	case "@method":
+		return req.Method, nil
+	case "@target-uri":
+		target := *req.URL
+		target.Fragment, target.RawFragment = "", ""
+		return target.String(), nil
+	case "@authority":
+		return requestAuthority(req), nil
+	case "@scheme":
+		return strings.ToLower(req.URL.Scheme), nil
+	case "@request-target":
+		return req.URL.RequestURI(), nil
+	case "@path":
+		if path := req.URL.EscapedPath(); path != "" {
+			return path, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + req.URL.RawQuery, nil
+	}
+	return "", fmt.Errorf("unsupported component %s", component)
+}
+
+// requestAuthority returns the lower-cased host of a request without a default port.
+func requestAuthority(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	host = strings.ToLower(host)
+	switch {
+	case req.URL.Scheme == "http" && strings.HasSuffix(host, ":80"):
+		return strings.TrimSuffix(host, ":80")
+	case req.URL.Scheme == "https" && strings.HasSuffix(host, ":443"):
+		return strings.TrimSuffix(host, ":443")
+	}
+	return host
+}
+
+// contentDigest computes the Content-Digest of a request body (RFC 9530), or
+// "" if the request has no body.
+func contentDigest(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	if req.GetBody == nil {
+		return "", fmt.Errorf("message signature: content-digest requires a replayable body")
+	}
+	body, err := openPayload(req)
+	if err != nil {
+		return "", fmt.Errorf("message signature: failed to read body: %w", err)
+	}
+	defer body.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, body); err != nil {
+		return "", fmt.Errorf("message signature: failed to read body: %w", err)
+	}
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(hasher.Sum(nil)) + ":", nil
+}
+
+// verifyContentDigest checks the body of a response as it is read against
+// its Content-Digest header.
+func verifyContentDigest(resp *http.Response, label string) error {
+	if responseScope(resp.StatusCode)&verifyContent == 0 || (resp.Request != nil && resp.Request.Method == http.MethodHead) {
+		return nil
+	}
+
+	verifier := &bodyVerifier{}
+	verifier.addField("Content-Digest", resp.Header.Get("Content-Digest"), false)
+	if len(verifier.checks) == 0 {
+		return &errors.SignatureError{Label: label, Reason: "Content-Digest has no supported algorithm"}
+	}
+	resp.Body = &progressReader{reader: resp.Body, total: resp.ContentLength, verifier: verifier}
+	return nil
+}
+
+// signMessage signs a signature base with the algorithm selected by the key type.
+func signMessage(key interface{}, base []byte) ([]byte, error) {
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(base)
+		return mac.Sum(nil), nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, base), nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("message signature: ECDSA keys must use P-256")
+		}
+		digest := sha256.Sum256(base)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return nil, fmt.Errorf("message signature: %w", err)
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	case *rsa.PrivateKey:
+		digest := sha512.Sum512(base)
+		signature, err := rsa.SignPSS(rand.Reader, key, crypto.SHA512, digest[:], &rsa.PSSOptions{SaltLength: 64})
+		if err != nil {
+			return nil, fmt.Errorf("message signature: %w", err)
+		}
+		return signature, nil
+	}
+	return nil, fmt.Errorf("message signature: unsupported key type %T", key)
+}
+
+// verifyMessage verifies a signature with the algorithm selected by the key
+// type, which must match alg if the signature names one.
+func verifyMessage(key interface{}, alg string, base, signature []byte) error {
+	switch private := key.(type) {
+	case ed25519.PrivateKey:
+		key = private.Public()
+	case *ecdsa.PrivateKey:
+		key = &private.PublicKey
+	case *rsa.PrivateKey:
+		key = &private.PublicKey
+	}
+
+	var expected string
+	var valid bool
+	switch key := key.(type) {
+	case []byte:
+		expected = "hmac-sha256"
+		mac := hmac.New(sha256.New, key)
+		mac.Write(base)
+		valid = hmac.Equal(mac.Sum(nil), signature)
+	case ed25519.PublicKey:
+		expected = "ed25519"
+		valid = ed25519.Verify(key, base, signature)
+	case *ecdsa.PublicKey:
+		expected = "ecdsa-p256-sha256"
+		if key.Curve != elliptic.P256() || len(signature) != 64 {
+			break
+		}
+		digest := sha256.Sum256(base)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		valid = ecdsa.Verify(key, digest[:], r, s)
+	case *rsa.PublicKey:
+		expected = "rsa-pss-sha512"
+		digest := sha512.Sum512(base)
+		valid = rsa.VerifyPSS(key, crypto.SHA512, digest[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+
+	if alg != "" && alg != expected {
+		return fmt.Errorf("algorithm %q does not match the %s key", alg, expected)
+	}
+	if !valid {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
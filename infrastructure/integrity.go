@@ -0,0 +1,215 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// Digest scopes select which checks apply to a body.
+const (
+	// verifyContent covers digests of the message content: Content-Digest and Content-MD5.
+	verifyContent = 1 << iota
+	// verifyRepresentation covers digests of the whole representation:
+	// Repr-Digest and the caller-supplied checksum.
+	verifyRepresentation
+)
+
+// digestAlgorithms maps RFC 9530 algorithm keys to their hash functions.
+// The deprecated md5 and sha keys are not accepted in digest fields.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// SetIntegrityOptions configures verification of response bodies.
+//
+// Example:
+//
+//	client.SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true})
+func (c *Client) SetIntegrityOptions(options *models.IntegrityOptions) *Client {
+	c.config.Integrity = options
+	return c
+}
+
+// digestCheck is a single expected digest and the hash computing the actual one.
+type digestCheck struct {
+	source         string
+	algorithm      string
+	expected       []byte
+	hash           hash.Hash
+	representation bool
+}
+
+// bodyVerifier hashes a body as it is read and compares it with the expected digests.
+type bodyVerifier struct {
+	checks []*digestCheck
+}
+
+// newBodyVerifier builds the checks that apply to a response body under the
+// given scope. It returns nil if there is nothing to verify. Digest headers
+// describe the encoded bytes, so if body was decompressed by the client, they
+// are checked as the compressed bytes are read from the wire instead and only
+// the expected checksum is left to the returned verifier. body may be nil.
+func newBodyVerifier(options *models.IntegrityOptions, header http.Header, scope int, body io.Reader) (*bodyVerifier, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	v := &bodyVerifier{}
+	wire := v
+	decoded := decodedBodyOf(body)
+	if decoded != nil {
+		wire = &bodyVerifier{}
+	}
+	if options.VerifyDigests {
+		wire.addDigests(header, scope)
+	}
+
+	if options.SHA256 != "" && scope&verifyRepresentation != 0 {
+		expected, err := hex.DecodeString(options.SHA256)
+		if err != nil || len(expected) != sha256.Size {
+			return nil, fmt.Errorf("invalid expected SHA-256 %q", options.SHA256)
+		}
+		v.add("checksum", "sha-256", expected, sha256.New, true)
+	}
+
+	if decoded != nil && len(wire.checks) > 0 {
+		decoded.wire.verifier = wire
+	}
+	if len(v.checks) == 0 {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// addDigests registers the digest headers of a response under the given scope.
+func (v *bodyVerifier) addDigests(header http.Header, scope int) {
+	if scope&verifyContent != 0 {
+		v.addField("Content-Digest", header.Get("Content-Digest"), false)
+		if value := header.Get("Content-MD5"); value != "" {
+			if expected, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value)); err == nil {
+				v.add("Content-MD5", "md5", expected, md5.New, false)
+			}
+		}
+	}
+	if scope&verifyRepresentation != 0 {
+		v.addField("Repr-Digest", header.Get("Repr-Digest"), true)
+	}
+}
+
+// responseScope returns the digest scope that applies to a response with the given status.
+// Partial content carries only part of the representation, and 204 and 304
+// responses carry none.
+func responseScope(statusCode int) int {
+	switch statusCode {
+	case http.StatusNoContent, http.StatusNotModified:
+		return 0
+	case http.StatusPartialContent:
+		return verifyContent
+	default:
+		return verifyContent | verifyRepresentation
+	}
+}
+
+// add registers an expected digest.
+func (v *bodyVerifier) add(source, algorithm string, expected []byte, newHash func() hash.Hash, representation bool) {
+	v.checks = append(v.checks, &digestCheck{
+		source:         source,
+		algorithm:      algorithm,
+		expected:       expected,
+		hash:           newHash(),
+		representation: representation,
+	})
+}
+
+// addField registers every supported digest of an RFC 9530 dictionary field
+// such as `sha-256=:base64:, sha-512=:base64:`.
+func (v *bodyVerifier) addField(source, value string, representation bool) {
+	digests := parseDigestField(value)
+
+	algorithms := make([]string, 0, len(digests))
+	for algorithm := range digests {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
+	for _, algorithm := range algorithms {
+		if newHash, ok := digestAlgorithms[algorithm]; ok {
+			v.add(source, algorithm, digests[algorithm], newHash, representation)
+		}
+	}
+}
+
+// Write feeds body bytes to every check.
+func (v *bodyVerifier) Write(p []byte) (int, error) {
+	for _, check := range v.checks {
+		check.hash.Write(p)
+	}
+	return len(p), nil
+}
+
+// prime feeds bytes received earlier, such as the start of a resumed download,
+// to the representation checks only.
+func (v *bodyVerifier) prime(r io.Reader) error {
+	writers := make([]io.Writer, 0, len(v.checks))
+	for _, check := range v.checks {
+		if check.representation {
+			writers = append(writers, check.hash)
+		}
+	}
+	if len(writers) == 0 {
+		return nil
+	}
+	_, err := io.Copy(io.MultiWriter(writers...), r)
+	return err
+}
+
+// verify compares the computed digests with the expected ones.
+func (v *bodyVerifier) verify() error {
+	for _, check := range v.checks {
+		actual := check.hash.Sum(nil)
+		if !bytes.Equal(actual, check.expected) {
+			return errors.NewIntegrityError(check.source, check.algorithm, check.expected, actual)
+		}
+	}
+	return nil
+}
+
+// parseDigestField parses an RFC 9530 digest field into raw digests keyed by
+// lowercase algorithm. Malformed members are skipped.
+func parseDigestField(value string) map[string][]byte {
+	digests := make(map[string][]byte)
+	for _, member := range strings.Split(value, ",") {
+		key, raw, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+
+		// Byte sequences are delimited by colons and may be followed by parameters
+		raw, _, _ = strings.Cut(raw, ";")
+		raw = strings.TrimSpace(raw)
+		if len(raw) < 2 || raw[0] != ':' || raw[len(raw)-1] != ':' {
+			continue
+		}
+
+		sum, err := base64.StdEncoding.DecodeString(raw[1 : len(raw)-1])
+		if err != nil {
+			continue
+		}
+		digests[strings.ToLower(strings.TrimSpace(key))] = sum
+	}
+	return digests
+}
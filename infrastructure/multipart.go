@@ -0,0 +1,337 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errBodyNotReplayable is returned when a one-shot body would have to be sent twice.
+var errBodyNotReplayable = errors.New("request body cannot be replayed")
+
+// streamBody is implemented by request bodies that stream themselves
+// instead of being serialized by a codec.
+type streamBody interface {
+	// open returns a fresh reader over the body for one attempt.
+	open() (io.ReadCloser, error)
+	// contentType returns the Content-Type header for the body, or "" to keep
+	// the one set on the request.
+	contentType() string
+	// size returns the body length in bytes, or -1 if unknown.
+	size() int64
+	// replayable reports whether open can be called more than once.
+	replayable() bool
+}
+
+// uploadPart is implemented by stream bodies that carry one part of a larger
+// upload, so upload progress reports offsets within the whole upload.
+type uploadPart interface {
+	// uploadOffset returns the offset of the part and the size of the whole upload.
+	uploadOffset() (offset, total int64)
+}
+
+// Multipart builds a multipart/form-data request body.
+// The body is streamed through an io.Pipe instead of being buffered in memory,
+// and its exact length is known when the size of every part is known.
+//
+// Example:
+//
+//	form := infrastructure.NewMultipart().
+//	    Field("title", "Holiday").
+//	    FileFromPath("photo", "./beach.jpg")
+//	_, err := client.Post(ctx, "/photos", nil, form, &result)
+type Multipart struct {
+	boundary string
+	parts    []*multipartPart
+	err      error
+}
+
+// multipartPart is a single part of a multipart body.
+type multipartPart struct {
+	header  textproto.MIMEHeader
+	value   []byte
+	source  func() (io.ReadCloser, error)
+	length  int64
+	oneShot bool
+}
+
+// NewMultipart creates an empty multipart/form-data body.
+func NewMultipart() *Multipart {
+	return &Multipart{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// Field adds a form field.
+func (m *Multipart) Field(name, value string) *Multipart {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", formDisposition(name, ""))
+	m.parts = append(m.parts, &multipartPart{
+		header: header,
+		value:  []byte(value),
+		length: int64(len(value)),
+	})
+	return m
+}
+
+// File adds a file part read from r with an application/octet-stream content type.
+func (m *Multipart) File(fieldName, fileName string, r io.Reader) *Multipart {
+	return m.FileWithContentType(fieldName, fileName, "application/octet-stream", r)
+}
+
+// FileWithContentType adds a file part read from r with the given content type.
+func (m *Multipart) FileWithContentType(fieldName, fileName, contentType string, r io.Reader) *Multipart {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", formDisposition(fieldName, fileName))
+	header.Set("Content-Type", contentType)
+	return m.Part(header, r)
+}
+
+// FileFromPath adds a file part read from disk. The file is reopened for
+// every attempt, so the body can be replayed on retries.
+func (m *Multipart) FileFromPath(fieldName, path string) *Multipart {
+	info, err := os.Stat(path)
+	if err != nil {
+		m.err = fmt.Errorf("multipart file %q: %w", path, err)
+		return m
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", formDisposition(fieldName, filepath.Base(path)))
+	header.Set("Content-Type", contentType)
+	m.parts = append(m.parts, &multipartPart{
+		header: header,
+		source: func() (io.ReadCloser, error) { return os.Open(path) },
+		length: info.Size(),
+	})
+	return m
+}
+
+// Part adds a part with custom headers read from r.
+// Seekable readers are rewound for every attempt; other readers can only be sent once.
+func (m *Multipart) Part(header textproto.MIMEHeader, r io.Reader) *Multipart {
+	source, length, replayable := readerSource(r)
+	m.parts = append(m.parts, &multipartPart{
+		header:  header,
+		source:  source,
+		length:  length,
+		oneShot: !replayable,
+	})
+	return m
+}
+
+// ContentType returns the multipart Content-Type including the boundary.
+func (m *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// Size returns the exact encoded length of the body, or -1 if any part has an unknown size.
+func (m *Multipart) Size() int64 {
+	counter := &countingWriter{}
+	mw := multipart.NewWriter(counter)
+	mw.SetBoundary(m.boundary)
+
+	var total int64
+	for _, part := range m.parts {
+		if part.length < 0 {
+			return -1
+		}
+		if _, err := mw.CreatePart(part.header); err != nil {
+			return -1
+		}
+		total += part.length
+	}
+	mw.Close()
+
+	return total + counter.n
+}
+
+// contentType implements streamBody.
+func (m *Multipart) contentType() string {
+	return m.ContentType()
+}
+
+// size implements streamBody.
+func (m *Multipart) size() int64 {
+	return m.Size()
+}
+
+// replayable implements streamBody. A body is replayable unless one of its
+// parts reads from a one-shot reader.
+func (m *Multipart) replayable() bool {
+	for _, part := range m.parts {
+		if part.oneShot {
+			return false
+		}
+	}
+	return true
+}
+
+// open implements streamBody by writing the parts into a pipe from a goroutine.
+func (m *Multipart) open() (io.ReadCloser, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.writeTo(pw))
+	}()
+	return pr, nil
+}
+
+// writeTo encodes all parts into w.
+func (m *Multipart) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range m.parts {
+		pw, err := mw.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+
+		if part.source == nil {
+			if _, err := pw.Write(part.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reader, err := part.source()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(pw, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+// readerSource turns a reader into a per-attempt source and detects its length.
+// Seekable readers are rewound to their starting offset on every call; other
+// readers are not replayable. Readers that also implement io.ReaderAt, such as
+// files and *bytes.Reader, are read through independent section readers, so
+// the body can be hashed while the copy being sent is still unread. Other
+// seekable readers are rewound on the first read of each copy instead.
+func readerSource(r io.Reader) (func() (io.ReadCloser, error), int64, bool) {
+	length := readerLength(r)
+
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if readerAt, ok := r.(io.ReaderAt); ok && err == nil && length >= 0 {
+			return func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(readerAt, start, length)), nil
+			}, length, true
+		}
+		if err == nil {
+			return func() (io.ReadCloser, error) {
+				return io.NopCloser(&rewindReader{reader: seeker, start: start}), nil
+			}, length, true
+		}
+	}
+
+	used := false
+	return func() (io.ReadCloser, error) {
+		if used {
+			return nil, errBodyNotReplayable
+		}
+		used = true
+		return io.NopCloser(r), nil
+	}, length, false
+}
+
+// rewindReader reads a seekable reader from its starting offset. It seeks on
+// the first read rather than when opened, so copies of the body that are read
+// one after the other, such as one for hashing and one for sending, each read
+// the whole body.
+type rewindReader struct {
+	reader  io.ReadSeeker
+	start   int64
+	rewound bool
+}
+
+// Read implements io.Reader.
+func (rr *rewindReader) Read(p []byte) (int, error) {
+	if !rr.rewound {
+		if _, err := rr.reader.Seek(rr.start, io.SeekStart); err != nil {
+			return 0, err
+		}
+		rr.rewound = true
+	}
+	return rr.reader.Read(p)
+}
+
+// readerLength returns the number of bytes left in r, or -1 if unknown.
+func readerLength(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(current, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - current
+	default:
+		return -1
+	}
+}
+
+// formDisposition builds a form-data Content-Disposition value the same way
+// mime/multipart does for CreateFormFile.
+func formDisposition(fieldName, fileName string) string {
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(fieldName))
+	if fileName != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(fileName))
+	}
+	return disposition
+}
+
+// quoteEscaper escapes quoted-string characters like mime/multipart does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"", "\r", "%0D", "\n", "%0A")
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+// Write implements io.Writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// ndjsonAccept is sent when neither the client nor the request sets an Accept header.
+const ndjsonAccept = "application/x-ndjson, application/jsonl, application/json;q=0.9"
+
+// StreamNDJSON executes a request whose response is newline-delimited JSON
+// (NDJSON / JSON Lines) and yields one decoded item per line.
+// Lines are read only as the caller iterates, so a slow consumer applies
+// backpressure to the connection. The client's data transformer runs on each
+// line before decoding, and blank lines are skipped. Iteration stops with an
+// error on the first malformed line, on read errors and when ctx is cancelled.
+//
+// Example:
+//
+//	for record, err := range infrastructure.StreamNDJSON[Record](ctx, client.R().Path("/export")) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(record)
+//	}
+func StreamNDJSON[T any](ctx context.Context, r *Request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		req := r.Clone()
+		if _, ok := lookupHeader(r.client.resolveConfig(req.config).Headers, "Accept"); !ok {
+			req.Header("Accept", ndjsonAccept)
+		}
+
+		resp, err := req.DoStream(ctx)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		reader := bufio.NewReader(resp.Body)
+		for lineNumber := 1; ; lineNumber++ {
+			line, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				yield(zero, fmt.Errorf("failed to read NDJSON line %d: %w", lineNumber, err))
+				return
+			}
+
+			if data := bytes.TrimSpace(line); len(data) > 0 {
+				item, decodeErr := decodeNDJSONLine[T](r.client, data)
+				if decodeErr != nil {
+					yield(zero, fmt.Errorf("NDJSON line %d: %w", lineNumber, decodeErr))
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if err == io.EOF {
+				return
+			}
+		}
+	}
+}
+
+// decodeNDJSONLine applies the data transformer to a line and decodes it as JSON.
+func decodeNDJSONLine[T any](c *Client, data []byte) (T, error) {
+	var item T
+
+	if c.dataTransformer != nil {
+		transformed, err := c.dataTransformer(data)
+		if err != nil {
+			return item, fmt.Errorf("data transformer error: %w", err)
+		}
+		data = transformed
+	}
+
+	if err := c.decodeBody(data, ContentTypeJSON, &item); err != nil {
+		return item, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return item, nil
+}
+
+// lookupHeader finds a header in a config header map regardless of key case.
+func lookupHeader(headers map[string]string, key string) (string, bool) {
+	canonical := http.CanonicalHeaderKey(key)
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == canonical {
+			return v, true
+		}
+	}
+	return "", false
+}
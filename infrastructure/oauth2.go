@@ -0,0 +1,335 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// defaultExpirySkew renews tokens shortly before they expire so they do not
+// expire in flight.
+const defaultExpirySkew = 10 * time.Second
+
+// SetOAuth2 authenticates requests with bearer tokens from an OAuth 2.0 token
+// endpoint. Tokens are cached until shortly before they expire and renewed
+// with the refresh token if the server issued one, otherwise with the
+// configured grant; concurrent requests share a single token request. A
+// request rejected with 401 and a Bearer invalid_token challenge is sent once
+// more with a new token. Tokens are only sent with relative request URLs and
+// URLs with the origin of the base URL or one of Origins; without either,
+// requests to absolute URLs fail. Requests with an Authorization header are
+// left alone. Nil disables OAuth2. Instances created with
+// NewInstance share the token.
+//
+// Example:
+//
+//	client.SetOAuth2(&models.OAuth2Options{
+//	    TokenURL:     "https://auth.example.com/oauth/token",
+//	    ClientID:     "service",
+//	    ClientSecret: secret,
+//	    Scopes:       []string{"orders:read"},
+//	})
+func (c *Client) SetOAuth2(options *models.OAuth2Options) *Client {
+	if options == nil {
+		c.oauth2 = nil
+		return c
+	}
+	c.oauth2 = newOAuth2Source(options)
+	return c
+}
+
+// OAuth2Token returns the current OAuth2 token, requesting a new one if it is
+// missing or about to expire.
+func (c *Client) OAuth2Token(ctx context.Context) (*models.OAuth2Token, error) {
+	if c.oauth2 == nil {
+		return nil, fmt.Errorf("oauth2: not configured")
+	}
+	return c.oauth2.get(ctx, c.httpClient, "")
+}
+
+// oauth2Source obtains, caches and renews the tokens of a client.
+type oauth2Source struct {
+	options models.OAuth2Options
+	grant   models.OAuth2Grant
+	skew    time.Duration
+
+	mu           sync.Mutex
+	token        *models.OAuth2Token
+	refreshToken string
+	call         *oauth2Call
+}
+
+// oauth2Call is a token request in flight, shared by concurrent callers.
+type oauth2Call struct {
+	done  chan struct{}
+	token *models.OAuth2Token
+	err   error
+}
+
+// oauth2Response is the JSON body of a token or error response (RFC 6749 section 5).
+type oauth2Response struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	RefreshToken     string      `json:"refresh_token"`
+	Scope            string      `json:"scope"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+	ErrorURI         string      `json:"error_uri"`
+}
+
+// newOAuth2Source creates a token source, applying defaults for unset options.
+func newOAuth2Source(options *models.OAuth2Options) *oauth2Source {
+	s := &oauth2Source{
+		options:      *options,
+		grant:        options.Grant,
+		skew:         options.ExpirySkew,
+		refreshToken: options.RefreshToken,
+	}
+	if s.grant == "" {
+		s.grant = models.GrantClientCredentials
+		if options.RefreshToken != "" {
+			s.grant = models.GrantRefreshToken
+		}
+	}
+	if s.skew == 0 {
+		s.skew = defaultExpirySkew
+	}
+	return s
+}
+
+// apply sets the Authorization header of a request to the base URL's origin
+// or one of the configured origins and returns the access token it was set
+// to, or "" for other origins.
+func (s *oauth2Source) apply(ctx context.Context, c *Client, req *http.Request, baseURL string) (string, error) {
+	allowed, err := allowedOrigin(s.options.Origins, baseURL, req.URL)
+	if err != nil {
+		return "", fmt.Errorf("oauth2: %w", err)
+	}
+	if !allowed {
+		return "", nil
+	}
+	token, err := s.get(ctx, c.httpClient, "")
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", authorizationValue(token))
+	return token.AccessToken, nil
+}
+
+// resend sends a request once more with a new token after the server
+// rejected the token it was sent with. The original response is returned if
+// the rejection is not an invalid_token challenge, the body cannot be replayed
+// or no new token can be obtained.
+func (s *oauth2Source) resend(ctx context.Context, c *Client, req *http.Request, resp *http.Response, send func(*http.Request) (*http.Response, error), sent string) (*http.Response, error) {
+	if resp.StatusCode != http.StatusUnauthorized || !canReplay(req) {
+		return resp, nil
+	}
+	challenge, ok := findChallenge(resp.Header, "bearer")
+	if !ok || challenge.params["error"] != "invalid_token" {
+		return resp, nil
+	}
+
+	token, err := s.get(ctx, c.httpClient, sent)
+	if err != nil {
+		return resp, nil
+	}
+	retry, err := replayRequest(ctx, req)
+	if err != nil {
+		return resp, nil
+	}
+	retry.Header.Set("Authorization", authorizationValue(token))
+
+	discardResponse(resp)
+	return send(retry)
+}
+
+// get returns a valid token other than stale, waiting for a token request
+// already in flight or starting one.
+func (s *oauth2Source) get(ctx context.Context, httpClient *http.Client, stale string) (*models.OAuth2Token, error) {
+	s.mu.Lock()
+	if s.token.Valid(s.skew) && s.token.AccessToken != stale {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	call := s.call
+	if call == nil {
+		// The request outlives a cancelled caller, other callers may be waiting for it
+		call = &oauth2Call{done: make(chan struct{})}
+		s.call = call
+		go s.fetch(context.WithoutCancel(ctx), httpClient, call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch requests a new token and completes the call with it.
+func (s *oauth2Source) fetch(ctx context.Context, httpClient *http.Client, call *oauth2Call) {
+	s.mu.Lock()
+	refreshToken := s.refreshToken
+	s.mu.Unlock()
+
+	token, err := s.request(ctx, httpClient, refreshToken)
+
+	s.mu.Lock()
+	if err == nil {
+		if token.RefreshToken == "" {
+			token.RefreshToken = refreshToken
+		}
+		s.token = token
+		s.refreshToken = token.RefreshToken
+	}
+	s.call = nil
+	s.mu.Unlock()
+
+	if err == nil && s.options.OnToken != nil {
+		s.options.OnToken(token)
+	}
+	call.token, call.err = token, err
+	close(call.done)
+}
+
+// request obtains a token with the refresh token if there is one, falling
+// back to the configured grant when the refresh token is rejected.
+func (s *oauth2Source) request(ctx context.Context, httpClient *http.Client, refreshToken string) (*models.OAuth2Token, error) {
+	if refreshToken != "" {
+		token, err := s.exchange(ctx, httpClient, url.Values{
+			"grant_type":    {string(models.GrantRefreshToken)},
+			"refresh_token": {refreshToken},
+		})
+		if err == nil || s.grant == models.GrantRefreshToken {
+			return token, err
+		}
+		if oauthErr, ok := err.(*errors.OAuth2Error); !ok || oauthErr.Code != "invalid_grant" {
+			return nil, err
+		}
+	}
+
+	params := url.Values{"grant_type": {string(s.grant)}}
+	switch s.grant {
+	case models.GrantClientCredentials:
+	case models.GrantPassword:
+		params.Set("username", s.options.Username)
+		params.Set("password", s.options.Password)
+	case models.GrantRefreshToken:
+		return nil, fmt.Errorf("oauth2: no refresh token")
+	default:
+		return nil, fmt.Errorf("oauth2: unsupported grant %q", s.grant)
+	}
+	return s.exchange(ctx, httpClient, params)
+}
+
+// exchange posts a token request and parses the token response.
+func (s *oauth2Source) exchange(ctx context.Context, httpClient *http.Client, params url.Values) (*models.OAuth2Token, error) {
+	if len(s.options.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.options.Scopes, " "))
+	}
+	for key, value := range s.options.EndpointParams {
+		params.Set(key, value)
+	}
+	if s.options.AuthStyle == models.AuthStyleParams {
+		params.Set("client_id", s.options.ClientID)
+		if s.options.ClientSecret != "" {
+			params.Set("client_secret", s.options.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.options.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentTypeForm)
+	req.Header.Set("Accept", ContentTypeJSON)
+	if s.options.AuthStyle != models.AuthStyleParams && s.options.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(s.options.ClientID), url.QueryEscape(s.options.ClientSecret))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStreamErrorBody))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read token response: %w", err)
+	}
+	parsed, parseErr := parseOAuth2Response(body, resp.Header.Get("Content-Type"))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || parsed.Error != "" {
+		return nil, &errors.OAuth2Error{
+			StatusCode:  resp.StatusCode,
+			Code:        parsed.Error,
+			Description: parsed.ErrorDescription,
+			URI:         parsed.ErrorURI,
+		}
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("oauth2: failed to parse token response: %w", parseErr)
+	}
+	if parsed.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response has no access_token")
+	}
+
+	token := &models.OAuth2Token{
+		AccessToken:  parsed.AccessToken,
+		TokenType:    parsed.TokenType,
+		RefreshToken: parsed.RefreshToken,
+		Scope:        parsed.Scope,
+	}
+	if seconds, err := strconv.ParseInt(parsed.ExpiresIn.String(), 10, 64); err == nil && seconds > 0 {
+		token.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// parseOAuth2Response parses a JSON token response, or a form-encoded one as
+// returned by some older providers.
+func parseOAuth2Response(body []byte, contentType string) (oauth2Response, error) {
+	var parsed oauth2Response
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == ContentTypeForm || mediaType == "text/plain" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return parsed, err
+		}
+		parsed.AccessToken = values.Get("access_token")
+		parsed.TokenType = values.Get("token_type")
+		parsed.RefreshToken = values.Get("refresh_token")
+		parsed.Scope = values.Get("scope")
+		parsed.ExpiresIn = json.Number(values.Get("expires_in"))
+		parsed.Error = values.Get("error")
+		parsed.ErrorDescription = values.Get("error_description")
+		parsed.ErrorURI = values.Get("error_uri")
+		return parsed, nil
+	}
+	err := json.Unmarshal(body, &parsed)
+	return parsed, err
+}
+
+// authorizationValue formats the Authorization header of a token. The token
+// type is case-insensitive, some servers return "bearer".
+func authorizationValue(token *models.OAuth2Token) string {
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + token.AccessToken
+}
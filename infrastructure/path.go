@@ -0,0 +1,166 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// expandPath fills the :name and {name} placeholders of a path template.
+// A :name placeholder starts a path segment and ends at the first character
+// that is not a letter, digit or underscore, so /files/:id.json and
+// /items/:idx are matched exactly. {name} may appear anywhere in a segment.
+// Each value is escaped as a single path segment, so a "/" in a value cannot
+// change the route. :name placeholders after "?" or "#" are left untouched.
+//
+// Other brace expressions, such as {/owner,repo} or {?page,per_page}, are
+// expanded as RFC 6570 URI templates, where undefined variables are omitted.
+// {name} is escaped like the RFC 6570 simple expansion, so a defined value
+// expands as it does in ExpandURITemplate, but an undefined one is an error.
+//
+// lookup returns the value of a placeholder and reports false if it has none.
+func expandPath(path string, encoder *queryEncoder, lookup func(name string) (interface{}, bool)) (string, error) {
+	var b strings.Builder
+	b.Grow(len(path))
+	inQuery := false
+
+	for i := 0; i < len(path); i++ {
+		var name string
+		braced := false
+		switch {
+		case path[i] == '{':
+			j := strings.IndexByte(path[i:], '}')
+			if j < 0 {
+				return "", fmt.Errorf("unterminated placeholder in path %q", path)
+			}
+			name = path[i+1 : i+j]
+			i += j
+			braced = true
+			if !isPlaceholderName(name, true) {
+				expanded, err := encoder.expandExpression(name, lookup)
+				if err != nil {
+					return "", fmt.Errorf("path %q: %w", path, err)
+				}
+				b.WriteString(expanded)
+				continue
+			}
+		case path[i] == ':' && !inQuery && (i == 0 || path[i-1] == '/') && i+1 < len(path) && isNameChar(path[i+1], false):
+			j := i + 1
+			for j < len(path) && isNameChar(path[j], false) {
+				j++
+			}
+			name = path[i+1 : j]
+			i = j - 1
+		default:
+			if path[i] == '?' || path[i] == '#' {
+				inQuery = true
+			}
+			b.WriteByte(path[i])
+			continue
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("missing path parameter %q", name)
+		}
+		segment, err := encoder.pathValue(value)
+		if err != nil {
+			return "", fmt.Errorf("path parameter %q: %w", name, err)
+		}
+		if braced {
+			b.WriteString(templateOperators[0].escape(segment))
+		} else {
+			b.WriteString(url.PathEscape(segment))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// resolveURL resolves an expanded path against the base URL as specified by
+// RFC 3986. The base path is treated as a directory that the path extends, so
+// with a base of https://api.example.com/v1, both "users" and "/users"
+// resolve to https://api.example.com/v1/users and "../v2/users" to
+// https://api.example.com/v2/users. An absolute URL replaces the base URL
+// unless allowAbsolute is false, and a scheme-relative //host/path takes the
+// scheme of the base URL. Query strings of the base URL and the path are both
+// kept.
+func resolveURL(baseURL, path string, allowAbsolute bool) (*url.URL, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Opaque != "" {
+		// A colon in the first segment, as in items:batchGet, is not a scheme
+		if ref, err = url.Parse("./" + path); err != nil {
+			return nil, err
+		}
+	}
+	if baseURL == "" {
+		return ref, nil
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	if ref.IsAbs() || ref.Host != "" {
+		if !allowAbsolute {
+			return nil, fmt.Errorf("absolute URL %q is not allowed to override the base URL", path)
+		}
+		// A scheme-relative //host/path takes the scheme of the base URL
+		return base.ResolveReference(ref), nil
+	}
+
+	query := joinQuery(base.RawQuery, ref.RawQuery)
+	base.RawQuery, base.ForceQuery, base.Fragment, base.RawFragment = "", false, "", ""
+
+	resolved := base
+	if ref.Path != "" {
+		if !strings.HasSuffix(base.Path, "/") {
+			base.Path += "/"
+			if base.RawPath != "" {
+				base.RawPath += "/"
+			}
+		}
+		ref.Path = strings.TrimLeft(ref.Path, "/")
+		ref.RawPath = strings.TrimLeft(ref.RawPath, "/")
+		resolved = base.ResolveReference(ref)
+	}
+
+	resolved.RawQuery = query
+	resolved.Fragment, resolved.RawFragment = ref.Fragment, ref.RawFragment
+	return resolved, nil
+}
+
+// joinQuery joins raw query strings with "&", skipping empty ones.
+func joinQuery(queries ...string) string {
+	var parts []string
+	for _, query := range queries {
+		if query != "" {
+			parts = append(parts, query)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// isPlaceholderName reports whether name is a valid placeholder name.
+// Braced names may also contain dots.
+func isPlaceholderName(name string, braced bool) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i], braced) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNameChar reports whether c may appear in a placeholder name.
+func isNameChar(c byte, braced bool) bool {
+	return c == '_' || (braced && c == '.') ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -2,16 +2,22 @@ package infrastructure
 
 import (
 	"io"
+	"sync"
 
 	"github.com/fourth-ally/gofetch/domain/contracts"
 )
 
 // progressReader wraps an io.Reader to track progress.
+// A total of -1 means the length is not known in advance.
+// If a verifier is set, the data is hashed as it passes through and
+// checked when the reader reaches EOF.
 type progressReader struct {
 	reader      io.Reader
 	total       int64
 	transferred int64
 	callback    contracts.ProgressCallback
+	transfer    contracts.TransferProgressCallback
+	verifier    *bodyVerifier
 }
 
 // Read implements io.Reader interface with progress tracking.
@@ -22,6 +28,60 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 	if pr.callback != nil {
 		pr.callback(pr.transferred, pr.total)
 	}
+	if pr.transfer != nil {
+		pr.transfer(pr.transferred, pr.transferred, pr.total)
+	}
+
+	if pr.verifier != nil {
+		pr.verifier.Write(p[:n])
+		if err == io.EOF {
+			if verifyErr := pr.verifier.verify(); verifyErr != nil {
+				return n, verifyErr
+			}
+		}
+	}
 
 	return n, err
 }
+
+// Close closes the underlying reader if it is an io.Closer, so that
+// streaming bodies are released when the transport is done with them.
+func (pr *progressReader) Close() error {
+	if closer, ok := pr.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// progressAggregator combines the progress of concurrent transfers into a
+// single callback. Calls to the callback are serialized.
+type progressAggregator struct {
+	mu       sync.Mutex
+	parts    []int64
+	total    int64
+	callback contracts.ProgressCallback
+}
+
+// newProgressAggregator creates an aggregator for the given number of parts.
+func newProgressAggregator(callback contracts.ProgressCallback, total int64, parts int) *progressAggregator {
+	return &progressAggregator{
+		parts:    make([]int64, parts),
+		total:    total,
+		callback: callback,
+	}
+}
+
+// reporter returns the progress callback for one part.
+func (pa *progressAggregator) reporter(part int) contracts.ProgressCallback {
+	return func(transferred, _ int64) {
+		pa.mu.Lock()
+		defer pa.mu.Unlock()
+
+		pa.parts[part] = transferred
+		var sum int64
+		for _, n := range pa.parts {
+			sum += n
+		}
+		pa.callback(sum, pa.total)
+	}
+}
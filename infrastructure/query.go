@@ -0,0 +1,446 @@
+package infrastructure
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// SetQueryOptions configures how parameter values are encoded in query strings.
+func (c *Client) SetQueryOptions(options *models.QueryOptions) *Client {
+	c.queryOptions = options
+	return c
+}
+
+// EncodeQuery encodes a struct or a map into query values.
+//
+// Struct fields are named by their url tag, or by the field name if the tag
+// has no name; "-" skips the field. Tag options:
+//   - omitempty skips zero values and empty slices and maps
+//   - repeat, comma or brackets override the array format for the field
+//   - unix, unixmilli or unixnano format a time as an epoch timestamp
+//   - int encodes a bool as 1 or 0
+//
+// A layout tag formats a time with a custom layout. Nested structs and maps
+// are encoded with bracketed keys, embedded structs are flattened, and values
+// implementing encoding.TextMarshaler are encoded as text. Other values are
+// formatted by their String method, or with %v.
+//
+// Example:
+//
+//	type ListOptions struct {
+//	    Query  string    `url:"q,omitempty"`
+//	    Tags   []string  `url:"tag,brackets"`
+//	    Since  time.Time `url:"since,omitempty" layout:"2006-01-02"`
+//	    Filter struct {
+//	        Status string `url:"status"`
+//	    } `url:"filter"`
+//	}
+//
+//	// q=go&tag[]=http&tag[]=client&filter[status]=open
+//	values, err := infrastructure.EncodeQuery(options, nil)
+func EncodeQuery(v interface{}, options *models.QueryOptions) (url.Values, error) {
+	values := url.Values{}
+	if err := newQueryEncoder(options).encodeParam(values, "", v); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// StructParams converts the fields of a struct into parameters for the
+// client's HTTP methods. Fields fill path placeholders or are added to the
+// query string, keeping the encoding options of their tags, see EncodeQuery.
+func StructParams(v interface{}) (map[string]interface{}, error) {
+	rv, ok := indirectValue(reflect.ValueOf(v))
+	if !ok || rv.Kind() != reflect.Struct || rv.Type() == timeType {
+		return nil, fmt.Errorf("struct params: expected a struct, got %T", v)
+	}
+
+	params := make(map[string]interface{})
+	walkFields(rv, func(name string, field reflect.Value, tag queryTag) error {
+		params[name] = taggedParam{value: field, tag: tag}
+		return nil
+	})
+	return params, nil
+}
+
+// queryValue is a value added with Request.Query or Request.QueryStruct,
+// encoded when the URL is built. An empty key flattens a struct or map.
+type queryValue struct {
+	key   string
+	value interface{}
+}
+
+// taggedParam is a struct field converted by StructParams.
+type taggedParam struct {
+	value reflect.Value
+	tag   queryTag
+}
+
+// queryTag holds the options of a url struct tag.
+type queryTag struct {
+	omitEmpty   bool
+	arrayFormat models.ArrayFormat
+	timeFormat  string
+	layout      string
+	boolInt     bool
+}
+
+// parseQueryTag returns the query name and options of a struct field.
+// It reports false if the field is skipped with url:"-".
+func parseQueryTag(field reflect.StructField) (string, queryTag, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("url"), ",")
+	if name == "-" && options == "" {
+		return "", queryTag{}, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	tag := queryTag{layout: field.Tag.Get("layout")}
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "omitempty":
+			tag.omitEmpty = true
+		case "repeat", "comma", "brackets":
+			tag.arrayFormat = models.ArrayFormat(option)
+		case "unix", "unixmilli", "unixnano":
+			tag.timeFormat = option
+		case "int":
+			tag.boolInt = true
+		}
+	}
+	return name, tag, true
+}
+
+// walkFields calls fn for each encodable field of a struct.
+// Embedded structs without a tag name are flattened into the parent.
+func walkFields(v reflect.Value, fn func(name string, field reflect.Value, tag queryTag) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tag, ok := parseQueryTag(field)
+		if !ok {
+			continue
+		}
+		value := v.Field(i)
+
+		if tagName, _, _ := strings.Cut(field.Tag.Get("url"), ","); field.Anonymous && tagName == "" {
+			embedded, ok := indirectValue(value)
+			if !ok {
+				continue
+			}
+			if embedded.Kind() == reflect.Struct && embedded.Type() != timeType {
+				if err := walkFields(embedded, fn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() || (tag.omitEmpty && isEmptyValue(value)) {
+			continue
+		}
+		if err := fn(name, value, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryEncoder encodes parameter values into query values.
+type queryEncoder struct {
+	arrayFormat models.ArrayFormat
+	timeLayout  string
+}
+
+// newQueryEncoder creates an encoder, applying defaults for unset options.
+func newQueryEncoder(options *models.QueryOptions) *queryEncoder {
+	e := &queryEncoder{arrayFormat: models.ArrayRepeat, timeLayout: time.RFC3339}
+	if options != nil {
+		if options.ArrayFormat != "" {
+			e.arrayFormat = options.ArrayFormat
+		}
+		if options.TimeLayout != "" {
+			e.timeLayout = options.TimeLayout
+		}
+	}
+	return e
+}
+
+// encodeParam adds the query values of a parameter. Nil values are omitted.
+func (e *queryEncoder) encodeParam(values url.Values, key string, value interface{}) error {
+	if param, ok := value.(taggedParam); ok {
+		return e.encode(values, key, param.value, param.tag)
+	}
+	return e.encode(values, key, reflect.ValueOf(value), queryTag{})
+}
+
+// pathValue formats a parameter for a path placeholder. Only single values are accepted.
+func (e *queryEncoder) pathValue(value interface{}) (string, error) {
+	v, tag := reflect.ValueOf(value), queryTag{}
+	if param, ok := value.(taggedParam); ok {
+		v, tag = param.value, param.tag
+	}
+
+	v, ok := indirectValue(v)
+	if !ok {
+		return "", fmt.Errorf("value is nil")
+	}
+	s, ok, err := e.scalar(v, tag)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return s, nil
+	}
+
+	// Lists, maps and structs only fill a placeholder with a String method
+	if stringer, ok := stringerValue(v); ok {
+		return stringer.String(), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// encode adds the query values for v under key.
+func (e *queryEncoder) encode(values url.Values, key string, v reflect.Value, tag queryTag) error {
+	v, ok := indirectValue(v)
+	if !ok {
+		return nil
+	}
+
+	s, ok, err := e.scalar(v, tag)
+	if err != nil {
+		return fmt.Errorf("query parameter %q: %w", key, err)
+	}
+	if ok || v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		if key == "" {
+			return fmt.Errorf("query value of type %s needs a key", v.Type())
+		}
+		if ok {
+			values.Add(key, s)
+			return nil
+		}
+		return e.encodeList(values, key, v, tag)
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		entries := make(map[string]reflect.Value, v.Len())
+		for _, mapKey := range v.MapKeys() {
+			name := fmt.Sprintf("%v", mapKey.Interface())
+			keys = append(keys, name)
+			entries[name] = v.MapIndex(mapKey)
+		}
+		sort.Strings(keys)
+
+		// Entries inherit the format options, but not omitempty
+		tag.omitEmpty = false
+		for _, name := range keys {
+			if err := e.encode(values, nestedKey(key, name), entries[name], tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return walkFields(v, func(name string, field reflect.Value, fieldTag queryTag) error {
+			return e.encode(values, nestedKey(key, name), field, fieldTag)
+		})
+	}
+
+	return fmt.Errorf("query parameter %q: unsupported type %s", key, v.Type())
+}
+
+// encodeList adds the elements of a slice or array in the configured format.
+// Structs and maps inside the list are encoded with indexed keys (a[0][b]=c).
+func (e *queryEncoder) encodeList(values url.Values, key string, v reflect.Value, tag queryTag) error {
+	format := tag.arrayFormat
+	if format == "" {
+		format = e.arrayFormat
+	}
+
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item, ok := indirectValue(v.Index(i))
+		if !ok {
+			continue
+		}
+
+		s, ok, err := e.scalar(item, tag)
+		if err != nil {
+			return fmt.Errorf("query parameter %q: %w", key, err)
+		}
+		if !ok {
+			if err := e.encode(values, key+"["+strconv.Itoa(i)+"]", item, tag); err != nil {
+				return err
+			}
+			continue
+		}
+		items = append(items, s)
+	}
+
+	switch format {
+	case models.ArrayComma:
+		if len(items) > 0 {
+			values.Add(key, strings.Join(items, ","))
+		}
+	case models.ArrayBrackets:
+		for _, item := range items {
+			values.Add(key+"[]", item)
+		}
+	default:
+		for _, item := range items {
+			values.Add(key, item)
+		}
+	}
+	return nil
+}
+
+// scalar formats v as a single query value. It reports false for slices,
+// maps and structs, which are encoded as several values. Values with a String
+// method and kinds without a structured encoding are formatted as they were
+// before structured encoding, with String or %v.
+func (e *queryEncoder) scalar(v reflect.Value, tag queryTag) (string, bool, error) {
+	if v.Type() == timeType && v.CanInterface() {
+		return e.formatTime(v.Interface().(time.Time), tag), true, nil
+	}
+	if marshaler, ok := textMarshaler(v); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), true, err
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		// Byte slices are sent as text rather than as a list of numbers
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+		return "", false, nil
+	case reflect.Array, reflect.Map, reflect.Struct:
+		return "", false, nil
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return "", false, fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	if stringer, ok := stringerValue(v); ok {
+		return stringer.String(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		if tag.boolInt {
+			if v.Bool() {
+				return "1", true, nil
+			}
+			return "0", true, nil
+		}
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	}
+
+	if !v.CanInterface() {
+		return "", false, fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return fmt.Sprintf("%v", v.Interface()), true, nil
+}
+
+// formatTime formats a time according to the field tag or the default layout.
+func (e *queryEncoder) formatTime(t time.Time, tag queryTag) string {
+	switch tag.timeFormat {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "unixnano":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	if tag.layout != "" {
+		return t.Format(tag.layout)
+	}
+	return t.Format(e.timeLayout)
+}
+
+// textMarshaler returns v as an encoding.TextMarshaler, including types
+// that implement it on a pointer receiver.
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+// stringerValue returns v as a fmt.Stringer, including types that implement
+// it on a pointer receiver.
+func stringerValue(v reflect.Value) (fmt.Stringer, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer), true
+	}
+	if reflect.PointerTo(v.Type()).Implements(stringerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(fmt.Stringer), true
+	}
+	return nil, false
+}
+
+// indirectValue dereferences pointers and interfaces. It reports false for nil values.
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// isEmptyValue reports whether v is omitted by omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// nestedKey returns the bracketed key of a nested value (a[b]).
+func nestedKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "[" + name + "]"
+}
@@ -0,0 +1,227 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// Request describes a single HTTP call built from a Client.
+// It can be inspected, cloned and executed later, and runs through the same
+// interceptors, retry manager and circuit breaker as the client's HTTP methods.
+type Request struct {
+	client     *Client
+	method     string
+	path       string
+	params     map[string]interface{}
+	pathParams map[string]interface{}
+	query      []queryValue
+	body       interface{}
+	stream     streamBody
+	target     interface{}
+	config     *models.Config
+}
+
+// R creates a new GET request bound to the client.
+//
+// Example:
+//
+//	resp, err := client.R().
+//	    Method("PROPFIND").
+//	    Path("/files/:id").
+//	    Param("id", 42).
+//	    Header("Depth", "1").
+//	    Into(&out).
+//	    Do(ctx)
+func (c *Client) R() *Request {
+	return &Request{
+		client: c,
+		method: http.MethodGet,
+		params: make(map[string]interface{}),
+	}
+}
+
+// Method sets the HTTP method. Any valid token is accepted, including
+// HEAD, OPTIONS and custom verbs such as PROPFIND or PURGE.
+func (r *Request) Method(method string) *Request {
+	r.method = method
+	return r
+}
+
+// Path sets the request path, which may contain :name or {name} placeholders
+// and RFC 6570 URI template expressions.
+//
+// :name and {name} are required placeholders: building the URL fails if one
+// has no value, and list values are rejected. A :name value is escaped with
+// url.PathEscape, and a {name} value like the RFC 6570 simple expansion, so
+// it expands as in ExpandURITemplate. Other expressions, such as {+path},
+// {/owner,repo} or {?page}, follow RFC 6570 and omit undefined variables.
+func (r *Request) Path(path string) *Request {
+	r.path = path
+	return r
+}
+
+// Param sets a parameter that fills a path placeholder or, if the path has
+// no matching placeholder, is added to the query string.
+func (r *Request) Param(key string, value interface{}) *Request {
+	r.params[key] = value
+	return r
+}
+
+// Params sets multiple parameters, see Param.
+func (r *Request) Params(params map[string]interface{}) *Request {
+	for key, value := range params {
+		r.params[key] = value
+	}
+	return r
+}
+
+// PathParam sets a parameter that only fills a path placeholder. Building
+// the URL fails if the path has no matching placeholder.
+func (r *Request) PathParam(key string, value interface{}) *Request {
+	if r.pathParams == nil {
+		r.pathParams = make(map[string]interface{})
+	}
+	r.pathParams[key] = value
+	return r
+}
+
+// PathParams sets multiple path parameters, see PathParam.
+func (r *Request) PathParams(params map[string]interface{}) *Request {
+	for key, value := range params {
+		r.PathParam(key, value)
+	}
+	return r
+}
+
+// Query adds a query string value. Repeated keys are preserved, and slices,
+// times, maps and structs are encoded as described in EncodeQuery.
+func (r *Request) Query(key string, value interface{}) *Request {
+	r.query = append(r.query, queryValue{key: key, value: value})
+	return r
+}
+
+// QueryStruct adds the fields of a struct, or the entries of a map, to the
+// query string, see EncodeQuery.
+func (r *Request) QueryStruct(v interface{}) *Request {
+	r.query = append(r.query, queryValue{value: v})
+	return r
+}
+
+// Body sets the request body. Values are serialized with the codec for the
+// request Content-Type, while an io.Reader or a func() (io.ReadCloser, error)
+// factory is streamed without buffering. Seekable readers and factories are
+// replayed on retries and redirects; retries are disabled for other readers,
+// which can only be sent once. A nil reader, such as a nil *bytes.Buffer, is
+// sent as no body.
+func (r *Request) Body(body interface{}) *Request {
+	r.body = requestBody(body)
+	r.stream = newStreamBody(r.body)
+	return r
+}
+
+// Into sets the target the response body is decoded into.
+func (r *Request) Into(target interface{}) *Request {
+	r.target = target
+	return r
+}
+
+// Header sets a header for this request only.
+func (r *Request) Header(key, value string) *Request {
+	r.ensureConfig().Headers[key] = value
+	return r
+}
+
+// Timeout sets the timeout for this request only.
+func (r *Request) Timeout(timeout time.Duration) *Request {
+	r.ensureConfig().Timeout = timeout
+	return r
+}
+
+// Integrity sets the integrity options for this request only.
+func (r *Request) Integrity(options *models.IntegrityOptions) *Request {
+	r.ensureConfig().Integrity = options
+	return r
+}
+
+// Config merges a per-request configuration into the request.
+func (r *Request) Config(config *models.Config) *Request {
+	if config == nil {
+		return r
+	}
+	r.config = r.ensureConfig().Merge(config)
+	return r
+}
+
+// ensureConfig lazily creates the per-request configuration.
+func (r *Request) ensureConfig() *models.Config {
+	if r.config == nil {
+		r.config = &models.Config{}
+	}
+	if r.config.Headers == nil {
+		r.config.Headers = make(map[string]string)
+	}
+	return r.config
+}
+
+// Clone creates a copy of the request that can be modified independently.
+// The body and target are shared with the original.
+func (r *Request) Clone() *Request {
+	clone := &Request{
+		client: r.client,
+		method: r.method,
+		path:   r.path,
+		params: make(map[string]interface{}, len(r.params)),
+		query:  append([]queryValue(nil), r.query...),
+		body:   r.body,
+		stream: r.stream,
+		target: r.target,
+	}
+
+	for key, value := range r.params {
+		clone.params[key] = value
+	}
+	if r.pathParams != nil {
+		clone.pathParams = make(map[string]interface{}, len(r.pathParams))
+		for key, value := range r.pathParams {
+			clone.pathParams[key] = value
+		}
+	}
+	if r.config != nil {
+		clone.config = r.config.Clone()
+	}
+
+	return clone
+}
+
+// GetMethod returns the HTTP method of the request.
+func (r *Request) GetMethod() string {
+	return r.method
+}
+
+// GetPath returns the unexpanded request path.
+func (r *Request) GetPath() string {
+	return r.path
+}
+
+// GetBody returns the request body.
+func (r *Request) GetBody() interface{} {
+	return r.body
+}
+
+// GetConfig returns a copy of the effective configuration, merged over the client configuration.
+func (r *Request) GetConfig() *models.Config {
+	return r.client.resolveConfig(r.config).Clone()
+}
+
+// URL returns the fully resolved request URL.
+func (r *Request) URL() (string, error) {
+	return r.client.buildURL(r.client.resolveConfig(r.config), r)
+}
+
+// Do executes the request.
+func (r *Request) Do(ctx context.Context) (*models.Response, error) {
+	return r.client.executeRequestWithRetry(ctx, r)
+}
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/fourth-ally/gofetch/domain/errors"
 	"github.com/fourth-ally/gofetch/domain/models"
 )
 
@@ -33,6 +34,21 @@ func (rm *RetryManager) ShouldRetry(attempt int, statusCode int, err error) bool
 		return false
 	}
 
+	// Corrupted bodies are retried regardless of the status code
+	if _, ok := err.(*errors.IntegrityError); ok {
+		return true
+	}
+
+	// Rejected token requests are only retried on server errors
+	if oauthErr, ok := err.(*errors.OAuth2Error); ok {
+		return oauthErr.StatusCode >= 500
+	}
+
+	// Invalid response signatures are not transient
+	if _, ok := err.(*errors.SignatureError); ok {
+		return false
+	}
+
 	// Retry on network errors
 	if err != nil {
 		return true
@@ -0,0 +1,255 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fourth-ally/gofetch/domain/contracts"
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// DownloadSegmented downloads a file in parallel byte ranges, see Request.DownloadSegmented.
+func (c *Client) DownloadSegmented(ctx context.Context, path string, dstFile string, segments int, configs ...*models.Config) (*models.DownloadResult, error) {
+	return c.newRequest(http.MethodGet, path, nil, nil, nil, configs).DownloadSegmented(ctx, dstFile, segments)
+}
+
+// DownloadSegmented downloads the response body into dstFile using up to
+// segments concurrent range requests.
+//
+// A HEAD request probes for Accept-Ranges and the size. The file is then
+// preallocated and each byte range is fetched over the same client, sharing
+// its headers, interceptors and circuit breaker, and written in place. Each
+// segment is retried and resumed on its own under the retry options. Download
+// progress is aggregated across segments. With integrity options set, each
+// segment is checked against its Content-Digest and the assembled file against
+// the probe's Repr-Digest and the expected checksum. When the server does not
+// support ranges or the size is unknown, it falls back to a single-stream Download.
+func (r *Request) DownloadSegmented(ctx context.Context, dstFile string, segments int) (*models.DownloadResult, error) {
+	c := r.client
+	config := c.resolveConfig(r.config)
+
+	probe, err := c.probeRanges(ctx, r, config)
+	if err != nil {
+		return nil, err
+	}
+
+	size := probe.ContentLength
+	if segments < 2 || size <= 0 || !strings.EqualFold(probe.Headers.Get("Accept-Ranges"), "bytes") {
+		return r.Download(ctx, dstFile)
+	}
+	if int64(segments) > size {
+		segments = int(size)
+	}
+
+	// Pin the segments to one version of the resource
+	validator := probe.Headers.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = probe.Headers.Get("Last-Modified")
+	}
+
+	partFile := dstFile + ".part"
+	file, err := os.OpenFile(partFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download file: %w", err)
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		os.Remove(partFile)
+		return nil, fmt.Errorf("failed to preallocate download file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progress *progressAggregator
+	if c.downloadProgress != nil {
+		progress = newProgressAggregator(c.downloadProgress, size, segments)
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	resumed := make([]int, segments)
+
+	segmentSize := size / int64(segments)
+	for i := 0; i < segments; i++ {
+		seg := &segment{
+			client:    c,
+			request:   r,
+			file:      file,
+			validator: validator,
+			start:     int64(i) * segmentSize,
+			end:       int64(i+1)*segmentSize - 1,
+		}
+		if i == segments-1 {
+			seg.end = size - 1
+		}
+		if progress != nil {
+			seg.progress = progress.reporter(i)
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := executeWithRetry(ctx, c, r, func(config *models.Config) (int64, int, error) {
+				return seg.attempt(ctx, config)
+			}, nil)
+			resumed[i] = seg.resumed
+
+			// The first failure cancels the remaining segments
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("segment %d-%d: %w", seg.start, seg.end, err)
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Representation digests can only be checked once every segment is in place
+	if firstErr == nil {
+		firstErr = verifySegmentedFile(file, config, probe.Headers)
+	}
+
+	closeErr := file.Close()
+	if firstErr == nil && closeErr != nil {
+		firstErr = fmt.Errorf("failed to write download file: %w", closeErr)
+	}
+	if firstErr != nil {
+		os.Remove(partFile)
+		return nil, firstErr
+	}
+
+	if err := os.Rename(partFile, dstFile); err != nil {
+		return nil, fmt.Errorf("failed to move download into place: %w", err)
+	}
+
+	result := &models.DownloadResult{
+		Path:       dstFile,
+		Size:       size,
+		StatusCode: http.StatusPartialContent,
+		Headers:    probe.Headers,
+	}
+	for _, n := range resumed {
+		result.Resumed += n
+	}
+	return result, nil
+}
+
+// probeRanges sends a HEAD request to learn the size and range support of a resource.
+// Servers that reject HEAD are treated as not supporting ranges.
+func (c *Client) probeRanges(ctx context.Context, r *Request, config *models.Config) (*models.StreamResponse, error) {
+	probe := r.Clone().Method(http.MethodHead)
+	resp, err := c.executeStream(ctx, probe, config.Merge(&models.Config{
+		Headers: map[string]string{"Accept-Encoding": "identity"},
+	}))
+	if err != nil {
+		if httpErr, ok := err.(*errors.HTTPError); ok {
+			return models.NewStreamResponse(httpErr.StatusCode, httpErr.Headers, -1, http.NoBody), nil
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// verifySegmentedFile checks the assembled file against Repr-Digest from the
+// probe and the expected checksum.
+func verifySegmentedFile(file *os.File, config *models.Config, headers http.Header) error {
+	verifier, err := newBodyVerifier(config.Integrity, headers, verifyRepresentation, nil)
+	if err != nil || verifier == nil {
+		return err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read download file: %w", err)
+	}
+	if err := verifier.prime(file); err != nil {
+		return fmt.Errorf("failed to read download file: %w", err)
+	}
+	return verifier.verify()
+}
+
+// segment is one byte range of a segmented download.
+type segment struct {
+	client    *Client
+	request   *Request
+	file      *os.File
+	validator string
+	start     int64
+	end       int64
+	written   int64
+	resumed   int
+	progress  contracts.ProgressCallback
+}
+
+// attempt fetches the rest of the segment and writes it at its offset.
+func (s *segment) attempt(ctx context.Context, config *models.Config) (int64, int, error) {
+	from := s.start + s.written
+	if s.written > 0 {
+		s.resumed++
+	}
+
+	headers := map[string]string{
+		"Accept-Encoding": "identity",
+		"Range":           fmt.Sprintf("bytes=%d-%d", from, s.end),
+	}
+	if s.validator != "" {
+		headers["If-Range"] = s.validator
+	}
+
+	resp, err := s.client.executeStream(ctx, s.request, config.Merge(&models.Config{Headers: headers}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, resp.StatusCode, fmt.Errorf("segmented download: expected 206 Partial Content, got %d (resource changed?)", resp.StatusCode)
+	}
+	if start, _, ok := parseContentRange(resp.Headers.Get("Content-Range")); !ok || start != from {
+		return 0, resp.StatusCode, fmt.Errorf("segmented download: unexpected Content-Range %q for offset %d", resp.Headers.Get("Content-Range"), from)
+	}
+
+	// Each response is checked against its own Content-Digest
+	verifier, err := newBodyVerifier(config.Integrity, resp.Headers, verifyContent, resp.Body)
+	if err != nil {
+		return 0, resp.StatusCode, err
+	}
+
+	length := s.end - s.start + 1
+	var body io.Reader = io.LimitReader(resp.Body, length-s.written)
+	if s.progress != nil || verifier != nil {
+		body = &progressReader{
+			reader:      body,
+			total:       length,
+			transferred: s.written,
+			callback:    s.progress,
+			verifier:    verifier,
+		}
+	}
+
+	n, err := io.Copy(io.NewOffsetWriter(s.file, from), body)
+	s.written += n
+	if integrityErr, ok := err.(*errors.IntegrityError); ok {
+		// Fetch the whole segment again
+		s.written = 0
+		return 0, resp.StatusCode, integrityErr
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("segment interrupted at offset %d: %w", s.start+s.written, err)
+	}
+	if s.written != length {
+		return 0, 0, fmt.Errorf("segment interrupted at offset %d: %w", s.start+s.written, io.ErrUnexpectedEOF)
+	}
+
+	return s.written, resp.StatusCode, nil
+}
@@ -0,0 +1,313 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+const (
+	sigV4Algorithm       = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat      = "20060102T150405Z"
+	sigV4UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// sigV4MaxExpires is the longest validity of a presigned URL.
+	sigV4MaxExpires = 7 * 24 * time.Hour
+)
+
+// sigV4IgnoredHeaders are not signed because proxies and the transport may
+// change them.
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":     true,
+	"user-agent":        true,
+	"x-amzn-trace-id":   true,
+	"expect":            true,
+	"transfer-encoding": true,
+}
+
+// SetSigV4 signs requests with AWS Signature Version 4. Requests are signed
+// as the last step before sending, after the request interceptors and body
+// encoding, so the signature covers the final headers and payload. Nil
+// disables signing.
+//
+// Example:
+//
+//	client.SetSigV4(&models.SigV4Options{
+//	    AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+//	    SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//	    Region:          "us-east-1",
+//	    Service:         "s3",
+//	})
+func (c *Client) SetSigV4(options *models.SigV4Options) *Client {
+	if options == nil {
+		c.sigv4 = nil
+		return c
+	}
+	c.sigv4 = NewSigV4Signer(options)
+	return c
+}
+
+// SigV4Signer signs HTTP requests with AWS Signature Version 4.
+type SigV4Signer struct {
+	options models.SigV4Options
+}
+
+// NewSigV4Signer creates a signer with the given credentials and scope.
+func NewSigV4Signer(options *models.SigV4Options) *SigV4Signer {
+	return &SigV4Signer{options: *options}
+}
+
+// Sign adds the X-Amz-Date and Authorization headers, and X-Amz-Security-Token
+// for temporary credentials. The payload hash is taken from an
+// X-Amz-Content-Sha256 header if the request has one, otherwise computed from
+// the body, which must then be replayable through GetBody. S3 requests get an
+// X-Amz-Content-Sha256 header.
+func (s *SigV4Signer) Sign(req *http.Request, signingTime time.Time) error {
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		var err error
+		if payloadHash, err = s.payloadHash(req); err != nil {
+			return err
+		}
+		if s.options.Service == "s3" {
+			req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+		}
+	}
+
+	signingTime = signingTime.UTC()
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", signingTime.Format(sigV4TimeFormat))
+	if s.options.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.options.SessionToken)
+	} else {
+		req.Header.Del("X-Amz-Security-Token")
+	}
+
+	signedHeaders, canonicalHeaders := sigV4Headers(req, false)
+	signature := s.signature(req, signingTime, canonicalHeaders, signedHeaders, req.URL.RawQuery, payloadHash)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.options.AccessKeyID, s.scope(signingTime), signedHeaders, signature))
+	return nil
+}
+
+// Presign returns the request URL with a query string signature valid for
+// expires, at most seven days. The payload is unsigned and, besides the host,
+// the headers the request has at this point must be sent with the URL.
+func (s *SigV4Signer) Presign(req *http.Request, expires time.Duration, signingTime time.Time) (string, error) {
+	if expires <= 0 || expires > sigV4MaxExpires {
+		return "", fmt.Errorf("sigv4: presigned URL expiry must be between 1s and 7 days, got %s", expires)
+	}
+
+	signingTime = signingTime.UTC()
+	signedHeaders, canonicalHeaders := sigV4Headers(req, true)
+
+	query := req.URL.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.options.AccessKeyID+"/"+s.scope(signingTime))
+	query.Set("X-Amz-Date", signingTime.Format(sigV4TimeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if s.options.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.options.SessionToken)
+	}
+	rawQuery := sigV4Query(query)
+
+	signature := s.signature(req, signingTime, canonicalHeaders, signedHeaders, rawQuery, sigV4UnsignedPayload)
+
+	presigned := *req.URL
+	presigned.RawQuery = rawQuery + "&X-Amz-Signature=" + signature
+	return presigned.String(), nil
+}
+
+// payloadHash returns the hex SHA-256 of the request body.
+func (s *SigV4Signer) payloadHash(req *http.Request) (string, error) {
+	if s.options.UnsignedPayload {
+		return sigV4UnsignedPayload, nil
+	}
+
+	hasher := sha256.New()
+	if req.GetBody != nil {
+		body, err := openPayload(req)
+		if err != nil {
+			return "", fmt.Errorf("sigv4: failed to read body: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(hasher, body); err != nil {
+			return "", fmt.Errorf("sigv4: failed to read body: %w", err)
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		return "", fmt.Errorf("sigv4: body cannot be hashed, set X-Amz-Content-Sha256 or enable UnsignedPayload")
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// signature computes the signature of a canonical request (AWS SigV4 tasks 1 to 3).
+func (s *SigV4Signer) signature(req *http.Request, signingTime time.Time, canonicalHeaders, signedHeaders, rawQuery, payloadHash string) string {
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4Path(req.URL, s.options.Service != "s3"),
+		sigV4CanonicalQuery(rawQuery),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		signingTime.Format(sigV4TimeFormat),
+		s.scope(signingTime),
+		hashHex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretAccessKey), signingTime.Format("20060102"))
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, s.options.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// scope returns the credential scope of a signing time.
+func (s *SigV4Signer) scope(signingTime time.Time) string {
+	return signingTime.Format("20060102") + "/" + s.options.Region + "/" + s.options.Service + "/aws4_request"
+}
+
+// sigV4Headers returns the signed header names and the canonical headers block.
+// The host is always signed.
+func sigV4Headers(req *http.Request, presign bool) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string][]string{"host": {host}}
+	for name, headerValues := range req.Header {
+		name = strings.ToLower(name)
+		if sigV4IgnoredHeaders[name] || (presign && strings.HasPrefix(name, "x-amz-")) {
+			continue
+		}
+		values[name] = headerValues
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		trimmed := make([]string, len(values[name]))
+		for i, value := range values[name] {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		canonical.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// sigV4Path returns the canonical URI. Each segment is URI-encoded once for
+// S3 and twice for other services, whose paths are also normalized first:
+// "." and ".." segments are resolved and repeated slashes collapsed.
+func sigV4Path(u *url.URL, doubleEncode bool) string {
+	escaped := u.EscapedPath()
+	if escaped == "" {
+		return "/"
+	}
+	if doubleEncode {
+		escaped = sigV4NormalizePath(escaped)
+	}
+	segments := strings.Split(escaped, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = sigV4Escape(segment)
+		if doubleEncode {
+			segment = sigV4Escape(segment)
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, "/")
+}
+
+// sigV4NormalizePath removes dot segments and empty segments from a path,
+// keeping a trailing slash.
+func sigV4NormalizePath(escaped string) string {
+	normalized := path.Clean("/" + escaped)
+	last := escaped[strings.LastIndexByte(escaped, '/')+1:]
+	if normalized != "/" && (last == "" || last == "." || last == "..") {
+		normalized += "/"
+	}
+	return normalized
+}
+
+// sigV4CanonicalQuery sorts and encodes the query parameters.
+func sigV4CanonicalQuery(rawQuery string) string {
+	query, _ := url.ParseQuery(rawQuery)
+	return sigV4Query(query)
+}
+
+// sigV4Query encodes query parameters sorted by name and value.
+func sigV4Query(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key)+"="+sigV4Escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// sigV4Escape percent-encodes everything but the RFC 3986 unreserved characters.
+func sigV4Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// Presign returns a SigV4 presigned URL for the request, valid for expires.
+// The URL covers the method, path and query parameters, not headers or body.
+func (r *Request) Presign(expires time.Duration) (string, error) {
+	if r.client.sigv4 == nil {
+		return "", fmt.Errorf("sigv4: not configured")
+	}
+	rawURL, err := r.URL()
+	if err != nil {
+		return "", fmt.Errorf("failed to build URL: %w", err)
+	}
+	req, err := http.NewRequest(r.method, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	return r.client.sigv4.Presign(req, expires, time.Now())
+}
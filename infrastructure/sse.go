@@ -0,0 +1,339 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// maxEventLineSize caps the length of a single line in an event stream.
+const maxEventLineSize = 4 << 20
+
+// EventSource consumes a Server-Sent Events (text/event-stream) endpoint.
+// It uses the client's base URL, headers and request interceptors, and
+// reconnects with Last-Event-ID using RetryManager backoff when the stream
+// drops. An EventSource must not be consumed by several goroutines at once.
+//
+// Example:
+//
+//	events := client.NewEventSource("/notifications", nil)
+//	for event, err := range events.Events(ctx) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(event.Event, event.Data)
+//	}
+type EventSource struct {
+	request        *Request
+	retryOptions   *models.RetryOptions
+	lastEventID    string
+	reconnectDelay time.Duration
+}
+
+// NewEventSource creates an event source for a GET endpoint.
+func (c *Client) NewEventSource(path string, params map[string]interface{}, configs ...*models.Config) *EventSource {
+	return &EventSource{request: c.newRequest(http.MethodGet, path, params, nil, nil, configs)}
+}
+
+// EventSource creates an event source from a built request, e.g. for
+// endpoints that stream events in response to a POST.
+func (r *Request) EventSource() *EventSource {
+	return &EventSource{request: r.Clone()}
+}
+
+// SetRetryOptions configures reconnection. MaxRetries is the number of
+// consecutive reconnection attempts without receiving an event before giving up.
+// By default the client's retry options are used, or NewRetryOptions if none are set.
+func (es *EventSource) SetRetryOptions(options *models.RetryOptions) *EventSource {
+	es.retryOptions = options
+	return es
+}
+
+// SetLastEventID sets the ID sent as Last-Event-ID on the next connection,
+// e.g. to resume from a previously persisted position.
+func (es *EventSource) SetLastEventID(id string) *EventSource {
+	es.lastEventID = id
+	return es
+}
+
+// LastEventID returns the last event ID received from the server.
+func (es *EventSource) LastEventID() string {
+	return es.lastEventID
+}
+
+// Events returns an iterator over the events of the stream. The iterator
+// reconnects transparently and ends when ctx is done, when the server answers
+// 204 No Content, or after yielding a terminal error.
+func (es *EventSource) Events(ctx context.Context) iter.Seq2[*models.ServerSentEvent, error] {
+	return func(yield func(*models.ServerSentEvent, error) bool) {
+		options := es.options()
+		retryManager := NewRetryManager(options)
+		failures := 0
+
+		for {
+			received, err := es.stream(ctx, yield)
+			if err == errStopStream {
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if received {
+				failures = 0
+			}
+
+			// Only connection failures and retryable statuses reconnect
+			if httpErr, ok := err.(*errors.HTTPError); ok && !options.ShouldRetryStatus(httpErr.StatusCode) {
+				yield(nil, err)
+				return
+			}
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			if failures >= options.MaxRetries {
+				yield(nil, fmt.Errorf("event source: giving up after %d reconnection attempts: %w", failures, err))
+				return
+			}
+
+			// A retry field from the server takes precedence over backoff
+			delay := es.reconnectDelay
+			if delay == 0 {
+				delay = retryManager.CalculateDelay(failures)
+			}
+			failures++
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// Subscribe streams events into a channel until ctx is done or the stream
+// fails terminally. The error channel receives at most one error; both
+// channels are closed when the stream ends. Sends block until the event is
+// received, so a slow consumer applies backpressure to the connection.
+func (es *EventSource) Subscribe(ctx context.Context) (<-chan *models.ServerSentEvent, <-chan error) {
+	events := make(chan *models.ServerSentEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		for event, err := range es.Events(ctx) {
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// errStopStream signals that the consumer stopped iterating or the server ended the stream.
+var errStopStream = fmt.Errorf("event stream stopped")
+
+// stream opens one connection and yields its events until it ends.
+// It reports whether any event was received.
+func (es *EventSource) stream(ctx context.Context, yield func(*models.ServerSentEvent, error) bool) (bool, error) {
+	req := es.request.Clone().
+		Header("Accept", "text/event-stream").
+		Header("Cache-Control", "no-cache")
+	if es.lastEventID != "" {
+		req.Header("Last-Event-ID", es.lastEventID)
+	}
+
+	client := es.request.client
+	resp, err := client.executeStream(ctx, req, client.resolveConfig(req.config))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// 204 No Content tells the client to stop reconnecting
+	if resp.StatusCode == http.StatusNoContent {
+		return false, errStopStream
+	}
+
+	if mediaType := normalizeMediaType(resp.Headers.Get("Content-Type")); mediaType != "text/event-stream" {
+		yield(nil, fmt.Errorf("event source: unexpected Content-Type %q", mediaType))
+		return false, errStopStream
+	}
+
+	received := false
+	parser := newEventParser(resp.Body, es.lastEventID)
+	for {
+		event, err := parser.next()
+
+		// Retry and id fields take effect even in a block that dispatches no event
+		if parser.retry > 0 {
+			es.reconnectDelay = parser.retry
+		}
+		es.lastEventID = parser.lastEventID
+
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return received, err
+		}
+
+		received = true
+
+		if !yield(event, nil) {
+			return received, errStopStream
+		}
+	}
+}
+
+// options returns the retry options used for reconnection.
+func (es *EventSource) options() *models.RetryOptions {
+	if es.retryOptions != nil {
+		return es.retryOptions
+	}
+	if options := es.request.client.resolveConfig(es.request.config).RetryOptions; options != nil {
+		return options
+	}
+	return models.NewRetryOptions()
+}
+
+// eventParser parses a text/event-stream as specified by the WHATWG HTML standard.
+type eventParser struct {
+	scanner *bufio.Scanner
+	// idBuffer holds the last id field, which becomes lastEventID when its
+	// block ends, even if the block dispatches no event.
+	idBuffer    string
+	lastEventID string
+	retry       time.Duration
+	first       bool
+}
+
+// newEventParser creates a parser that starts with the given last event ID.
+func newEventParser(r io.Reader, lastEventID string) *eventParser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxEventLineSize)
+	scanner.Split(scanEventLines)
+
+	return &eventParser{
+		scanner:     scanner,
+		idBuffer:    lastEventID,
+		lastEventID: lastEventID,
+		first:       true,
+	}
+}
+
+// next returns the next dispatched event, or io.EOF when the stream ends.
+// An incomplete event at the end of the stream is discarded.
+func (p *eventParser) next() (*models.ServerSentEvent, error) {
+	var data strings.Builder
+	var eventType string
+	var retry time.Duration
+	hasData := false
+
+	for p.scanner.Scan() {
+		line := p.scanner.Text()
+		if p.first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			p.first = false
+		}
+
+		// A blank line dispatches the event
+		if line == "" {
+			p.lastEventID = p.idBuffer
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return &models.ServerSentEvent{
+				ID:    p.lastEventID,
+				Event: eventType,
+				Data:  strings.TrimSuffix(data.String(), "\n"),
+				Retry: retry,
+			}, nil
+		}
+
+		// Comments keep the connection alive and are ignored
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				p.idBuffer = value
+			}
+		case "retry":
+			if milliseconds, err := strconv.ParseUint(value, 10, 63); err == nil {
+				retry = time.Duration(milliseconds) * time.Millisecond
+				p.retry = retry
+			}
+		}
+	}
+
+	if err := p.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// scanEventLines splits on CRLF, LF or CR line endings.
+func scanEventLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR may be followed by an LF in the next read
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// maxStreamErrorBody caps how much of a rejected streaming response is read into the HTTPError.
+const maxStreamErrorBody = 1 << 20
+
+// DoStream executes the request and returns the response body unread.
+// Request and response interceptors, status validation, retries and the circuit
+// breaker all apply before the body is handed over; the data transformer and
+// codecs do not. The configured timeout bounds the wait for response headers,
+// while reading the body is bounded only by ctx. The caller must close the body.
+func (r *Request) DoStream(ctx context.Context) (*models.StreamResponse, error) {
+	return r.client.executeStreamWithRetry(ctx, r)
+}
+
+// GetStream performs a GET request and returns the response body unread, see Request.DoStream.
+func (c *Client) GetStream(ctx context.Context, path string, params map[string]interface{}, configs ...*models.Config) (*models.StreamResponse, error) {
+	return c.executeStreamWithRetry(ctx, c.newRequest(http.MethodGet, path, params, nil, nil, configs))
+}
+
+// executeStreamWithRetry wraps executeStream with retry logic and circuit breaker.
+func (c *Client) executeStreamWithRetry(ctx context.Context, r *Request) (*models.StreamResponse, error) {
+	return executeWithRetry(ctx, c, r, func(config *models.Config) (*models.StreamResponse, int, error) {
+		resp, err := c.executeStream(ctx, r, config)
+		if resp == nil {
+			return nil, 0, err
+		}
+
+		var verifier *bodyVerifier
+		if r.method != http.MethodHead {
+			verifier, err = newBodyVerifier(config.Integrity, resp.Headers, responseScope(resp.StatusCode), resp.Body)
+			if err != nil {
+				resp.Body.Close()
+				return nil, 0, err
+			}
+		}
+
+		c.trackDownload(resp, 0, resp.ContentLength, verifier)
+		return resp, resp.StatusCode, nil
+	}, func(resp *models.StreamResponse) {
+		resp.Body.Close()
+	})
+}
+
+// executeStream executes a request and validates its status without reading the body.
+func (c *Client) executeStream(ctx context.Context, r *Request, config *models.Config) (*models.StreamResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// The timeout only covers the wait for headers, the body may take much longer
+	var timer *time.Timer
+	if config.Timeout > 0 {
+		timer = time.AfterFunc(config.Timeout, cancel)
+	}
+
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	resp, err := c.sendRequest(ctx, r, config, &httpClient)
+	if timer != nil && !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("request execution error: no response headers within %v: %w", config.Timeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Validate status code before handing out the body
+	if !config.StatusValidator(resp.StatusCode) {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxStreamErrorBody))
+		resp.Body.Close()
+		cancel()
+		return nil, errors.NewHTTPError(resp, respBody, "")
+	}
+
+	stream := models.NewStreamResponse(resp.StatusCode, resp.Header, resp.ContentLength, &streamReadCloser{
+		reader: resp.Body,
+		closer: resp.Body,
+		cancel: cancel,
+	})
+	stream.Uncompressed = resp.Uncompressed
+	return stream, nil
+}
+
+// trackDownload wraps a streamed body with download progress tracking and,
+// if verifier is not nil, integrity verification when the body reaches EOF.
+// offset is the number of bytes already transferred before this body.
+func (c *Client) trackDownload(resp *models.StreamResponse, offset, total int64, verifier *bodyVerifier) {
+	callback, transfer := c.downloadProgress, c.transferProgress
+	if c.trackDecoded(resp.Body) {
+		callback, transfer = nil, nil
+	}
+
+	if callback == nil && transfer == nil && verifier == nil {
+		return
+	}
+	resp.Body = &progressReader{
+		reader:      resp.Body,
+		total:       total,
+		transferred: offset,
+		callback:    callback,
+		transfer:    transfer,
+		verifier:    verifier,
+	}
+}
+
+// streamReadCloser is a streamed response body that releases the request
+// context once the caller closes it.
+type streamReadCloser struct {
+	reader io.Reader
+	closer io.Closer
+	cancel context.CancelFunc
+}
+
+// Read implements io.Reader.
+func (s *streamReadCloser) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Close implements io.Closer.
+func (s *streamReadCloser) Close() error {
+	err := s.closer.Close()
+	s.cancel()
+	return err
+}
@@ -0,0 +1,321 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+const (
+	// tusVersion is the tus protocol version sent in Tus-Resumable.
+	tusVersion = "1.0.0"
+
+	// tusContentType is the Content-Type of PATCH request bodies.
+	tusContentType = "application/offset+octet-stream"
+
+	// defaultTusChunkSize is the default number of bytes sent per PATCH request.
+	defaultTusChunkSize = 4 << 20
+)
+
+// TusUpload uploads data with the tus 1.0 resumable upload protocol.
+//
+// The upload is created with a POST to the creation endpoint, then sent in
+// chunks with PATCH requests carrying Upload-Offset. A failed chunk is retried
+// with RetryManager backoff after asking the server for the current offset
+// with HEAD, so only the missing bytes are sent again. Requests go through the
+// client's headers, interceptors and circuit breaker, and upload progress
+// reports offsets within the whole upload.
+//
+// Example:
+//
+//	file, _ := os.Open("video.mp4")
+//	info, _ := file.Stat()
+//	upload := client.NewTusUpload("/files", file, info.Size()).
+//	    SetMetadata("filename", "video.mp4")
+//	if err := upload.Upload(ctx); err != nil {
+//	    saveForLater(upload.URL()) // resume with SetURL
+//	}
+type TusUpload struct {
+	client       *Client
+	endpoint     string
+	config       *models.Config
+	source       io.ReaderAt
+	size         int64
+	metadata     map[string]string
+	chunkSize    int64
+	retryOptions *models.RetryOptions
+	location     string
+	offset       int64
+}
+
+// NewTusUpload creates an upload of size bytes read from source to a tus creation endpoint.
+func (c *Client) NewTusUpload(endpoint string, source io.ReaderAt, size int64, configs ...*models.Config) *TusUpload {
+	return &TusUpload{
+		client:    c,
+		endpoint:  endpoint,
+		config:    mergeRequestConfigs(configs),
+		source:    source,
+		size:      size,
+		metadata:  make(map[string]string),
+		chunkSize: defaultTusChunkSize,
+	}
+}
+
+// SetMetadata adds an Upload-Metadata entry sent when the upload is created.
+func (u *TusUpload) SetMetadata(key, value string) *TusUpload {
+	u.metadata[key] = value
+	return u
+}
+
+// SetChunkSize sets the maximum number of bytes sent per PATCH request.
+func (u *TusUpload) SetChunkSize(size int64) *TusUpload {
+	if size > 0 {
+		u.chunkSize = size
+	}
+	return u
+}
+
+// SetRetryOptions configures the retries of each request of the upload.
+// By default the client's retry options are used.
+func (u *TusUpload) SetRetryOptions(options *models.RetryOptions) *TusUpload {
+	u.retryOptions = options
+	return u
+}
+
+// SetURL sets the URL of an upload created earlier, so Upload resumes it
+// instead of creating a new one.
+func (u *TusUpload) SetURL(uploadURL string) *TusUpload {
+	u.location = uploadURL
+	return u
+}
+
+// URL returns the upload URL, or "" if the upload has not been created yet.
+func (u *TusUpload) URL() string {
+	return u.location
+}
+
+// Offset returns the number of bytes the server has confirmed.
+func (u *TusUpload) Offset() int64 {
+	return u.offset
+}
+
+// Upload creates the upload if needed and sends the remaining data.
+// When resuming an upload set with SetURL, the offset is first recovered with HEAD.
+func (u *TusUpload) Upload(ctx context.Context) error {
+	if u.location == "" {
+		if err := u.create(ctx); err != nil {
+			return err
+		}
+	} else {
+		if _, err := executeWithRetry(ctx, u.client, u.request(http.MethodHead), func(config *models.Config) (int64, int, error) {
+			return u.recoverOffset(ctx, config)
+		}, nil); err != nil {
+			return fmt.Errorf("tus: failed to recover offset: %w", err)
+		}
+	}
+
+	for u.offset < u.size {
+		recovering := false
+		_, err := executeWithRetry(ctx, u.client, u.request(http.MethodPatch), func(config *models.Config) (int64, int, error) {
+			// After a failure the server may have stored part of the chunk
+			if recovering {
+				offset, statusCode, err := u.recoverOffset(ctx, config)
+				if err != nil || offset == u.size {
+					return offset, statusCode, err
+				}
+			}
+
+			offset, statusCode, err := u.patch(ctx, config)
+			recovering = err != nil
+			return offset, statusCode, err
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("tus: upload failed at offset %d: %w", u.offset, err)
+		}
+	}
+
+	return nil
+}
+
+// Terminate deletes the upload on the server with the termination extension.
+func (u *TusUpload) Terminate(ctx context.Context) error {
+	if u.location == "" {
+		return fmt.Errorf("tus: upload has not been created")
+	}
+
+	if _, err := u.request(http.MethodDelete).Do(ctx); err != nil {
+		return fmt.Errorf("tus: termination failed: %w", err)
+	}
+	u.location = ""
+	u.offset = 0
+	return nil
+}
+
+// create sends the creation request and stores the upload URL.
+func (u *TusUpload) create(ctx context.Context) error {
+	req := u.client.R().
+		Method(http.MethodPost).
+		Path(u.endpoint).
+		Config(u.requestConfig()).
+		Header("Tus-Resumable", tusVersion).
+		Header("Upload-Length", strconv.FormatInt(u.size, 10))
+	if len(u.metadata) > 0 {
+		req.Header("Upload-Metadata", u.encodeMetadata())
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return fmt.Errorf("tus: creation failed: %w", err)
+	}
+
+	location := resp.Headers.Get("Location")
+	if location == "" {
+		return fmt.Errorf("tus: creation response %d has no Location header", resp.StatusCode)
+	}
+
+	// The Location may be relative to the creation URL
+	endpointURL, err := req.URL()
+	if err != nil {
+		return fmt.Errorf("tus: failed to build URL: %w", err)
+	}
+	base, err := url.Parse(endpointURL)
+	if err != nil {
+		return fmt.Errorf("tus: invalid creation URL: %w", err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("tus: invalid Location %q: %w", location, err)
+	}
+
+	u.location = base.ResolveReference(ref).String()
+	u.offset = 0
+	return nil
+}
+
+// recoverOffset asks the server for the current offset of the upload with HEAD.
+func (u *TusUpload) recoverOffset(ctx context.Context, config *models.Config) (int64, int, error) {
+	resp, err := u.client.executeRequest(ctx, u.request(http.MethodHead), config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err := u.parseOffset(resp.Headers)
+	if err != nil {
+		return 0, resp.StatusCode, err
+	}
+	u.offset = offset
+	return offset, resp.StatusCode, nil
+}
+
+// patch sends the next chunk from the current offset.
+func (u *TusUpload) patch(ctx context.Context, config *models.Config) (int64, int, error) {
+	length := u.size - u.offset
+	if length > u.chunkSize {
+		length = u.chunkSize
+	}
+
+	req := u.request(http.MethodPatch).
+		Header("Upload-Offset", strconv.FormatInt(u.offset, 10)).
+		Body(&tusChunk{source: u.source, offset: u.offset, length: length, total: u.size})
+
+	resp, err := u.client.executeRequest(ctx, req, config.Merge(req.config))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err := u.parseOffset(resp.Headers)
+	if err != nil {
+		return 0, resp.StatusCode, err
+	}
+	if offset <= u.offset {
+		return 0, resp.StatusCode, fmt.Errorf("tus: server did not advance the offset past %d", u.offset)
+	}
+	u.offset = offset
+	return offset, resp.StatusCode, nil
+}
+
+// request builds a request to the upload URL.
+func (u *TusUpload) request(method string) *Request {
+	config := u.requestConfig()
+	config.BaseURL = u.location
+
+	return u.client.R().
+		Method(method).
+		Config(config).
+		Header("Tus-Resumable", tusVersion)
+}
+
+// requestConfig returns the per-request configuration shared by all requests of the upload.
+func (u *TusUpload) requestConfig() *models.Config {
+	config := &models.Config{RetryOptions: u.retryOptions}
+	if u.config != nil {
+		config = u.config.Merge(config)
+	}
+	return config
+}
+
+// parseOffset reads a valid Upload-Offset header.
+func (u *TusUpload) parseOffset(headers http.Header) (int64, error) {
+	value := headers.Get("Upload-Offset")
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || offset < 0 || offset > u.size {
+		return 0, fmt.Errorf("tus: invalid Upload-Offset %q", value)
+	}
+	return offset, nil
+}
+
+// encodeMetadata formats Upload-Metadata as sorted "key base64(value)" pairs.
+func (u *TusUpload) encodeMetadata() string {
+	keys := make([]string, 0, len(u.metadata))
+	for key := range u.metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(u.metadata[key])))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// tusChunk is the body of one PATCH request, read from the source at its offset.
+type tusChunk struct {
+	source io.ReaderAt
+	offset int64
+	length int64
+	total  int64
+}
+
+// open implements streamBody.
+func (tc *tusChunk) open() (io.ReadCloser, error) {
+	return io.NopCloser(io.NewSectionReader(tc.source, tc.offset, tc.length)), nil
+}
+
+// contentType implements streamBody.
+func (tc *tusChunk) contentType() string {
+	return tusContentType
+}
+
+// size implements streamBody.
+func (tc *tusChunk) size() int64 {
+	return tc.length
+}
+
+// replayable implements streamBody.
+func (tc *tusChunk) replayable() bool {
+	return true
+}
+
+// uploadOffset implements uploadPart.
+func (tc *tusChunk) uploadOffset() (int64, int64) {
+	return tc.offset, tc.total
+}
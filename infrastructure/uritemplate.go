@@ -0,0 +1,373 @@
+package infrastructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExpandURITemplate expands an RFC 6570 URI template (levels 1 to 4) with the
+// given values. Scalars are formatted like query parameters, slices are lists
+// and maps or structs are associative arrays. Undefined variables, including
+// nil values and empty lists, are omitted as the RFC requires.
+//
+// Templates can also be passed directly as the path of a request, see Request.Path.
+//
+// Example:
+//
+//	// /repos/octo/hello?page=2
+//	link, err := infrastructure.ExpandURITemplate("/repos{/owner,repo}{?page,per_page}",
+//	    map[string]interface{}{"owner": "octo", "repo": "hello", "page": 2})
+func ExpandURITemplate(template string, values map[string]interface{}) (string, error) {
+	encoder := newQueryEncoder(nil)
+	lookup := func(name string) (interface{}, bool) {
+		value, ok := values[name]
+		return value, ok && value != nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			b.WriteString(template)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated expression in URI template %q", template)
+		}
+
+		b.WriteString(template[:start])
+		expanded, err := encoder.expandExpression(template[start+1:start+end], lookup)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expanded)
+		template = template[start+end+1:]
+	}
+}
+
+// templateOperator describes the expansion behavior of an RFC 6570 operator.
+type templateOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+// templateOperators maps each operator to its behavior (RFC 6570, Appendix A).
+var templateOperators = map[byte]templateOperator{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// varSpec is a variable of an expression with its modifiers.
+type varSpec struct {
+	name      string
+	maxLength int
+	explode   bool
+}
+
+// templateValue is the value of a variable: a string, a list or an associative array.
+type templateValue struct {
+	scalar *string
+	list   []string
+	keys   []string
+	pairs  map[string]string
+}
+
+// expandExpression expands the expression between braces of an RFC 6570 template.
+func (e *queryEncoder) expandExpression(expression string, lookup func(name string) (interface{}, bool)) (string, error) {
+	op := templateOperators[0]
+	if expression != "" {
+		if o, found := templateOperators[expression[0]]; found {
+			op, expression = o, expression[1:]
+		}
+	}
+	if expression == "" {
+		return "", fmt.Errorf("empty URI template expression")
+	}
+
+	var b strings.Builder
+	defined := 0
+	for _, spec := range strings.Split(expression, ",") {
+		v, err := parseVarSpec(spec)
+		if err != nil {
+			return "", err
+		}
+
+		raw, ok := lookup(v.name)
+		if !ok {
+			continue
+		}
+		value, err := e.templateValue(raw)
+		if err != nil {
+			return "", fmt.Errorf("URI template variable %q: %w", v.name, err)
+		}
+		if value == nil {
+			continue
+		}
+		if value.scalar == nil && v.maxLength > 0 {
+			return "", fmt.Errorf("URI template variable %q: prefix modifier applied to a composite value", v.name)
+		}
+
+		if defined == 0 {
+			b.WriteString(op.first)
+		} else {
+			b.WriteString(op.sep)
+		}
+		defined++
+		op.expandVariable(&b, v, value)
+	}
+
+	return b.String(), nil
+}
+
+// expandVariable writes the expansion of a single defined variable.
+func (op templateOperator) expandVariable(b *strings.Builder, v varSpec, value *templateValue) {
+	// writeNamed writes name=value, or name followed by ifEmpty for an empty value
+	writeNamed := func(name, s string) {
+		b.WriteString(op.escape(name))
+		if s == "" {
+			b.WriteString(op.ifEmpty)
+			return
+		}
+		b.WriteByte('=')
+		b.WriteString(s)
+	}
+
+	switch {
+	case value.scalar != nil:
+		s := *value.scalar
+		if v.maxLength > 0 {
+			s = truncateRunes(s, v.maxLength)
+		}
+		if op.named {
+			writeNamed(v.name, op.escape(s))
+		} else {
+			b.WriteString(op.escape(s))
+		}
+
+	case !v.explode:
+		parts := make([]string, 0, len(value.list)+2*len(value.keys))
+		for _, item := range value.list {
+			parts = append(parts, op.escape(item))
+		}
+		for _, key := range value.keys {
+			parts = append(parts, op.escape(key), op.escape(value.pairs[key]))
+		}
+		if op.named {
+			writeNamed(v.name, strings.Join(parts, ","))
+		} else {
+			b.WriteString(strings.Join(parts, ","))
+		}
+
+	case value.list != nil:
+		for i, item := range value.list {
+			if i > 0 {
+				b.WriteString(op.sep)
+			}
+			if op.named {
+				writeNamed(v.name, op.escape(item))
+			} else {
+				b.WriteString(op.escape(item))
+			}
+		}
+
+	default:
+		for i, key := range value.keys {
+			if i > 0 {
+				b.WriteString(op.sep)
+			}
+			if op.named {
+				writeNamed(key, op.escape(value.pairs[key]))
+				continue
+			}
+			b.WriteString(op.escape(key))
+			b.WriteByte('=')
+			b.WriteString(op.escape(value.pairs[key]))
+		}
+	}
+}
+
+// escape percent-encodes s, keeping reserved characters and existing
+// percent-encoded triplets for the + and # operators.
+func (op templateOperator) escape(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+		case op.reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			b.WriteByte(c)
+		case op.reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
+
+// parseVarSpec parses a variable name with an optional :maxlength or * modifier.
+func parseVarSpec(spec string) (varSpec, error) {
+	v := varSpec{name: spec}
+	if name, ok := strings.CutSuffix(spec, "*"); ok {
+		v.name, v.explode = name, true
+	} else if name, length, ok := strings.Cut(spec, ":"); ok {
+		maxLength, err := strconv.Atoi(length)
+		if err != nil || maxLength <= 0 || maxLength >= 10000 {
+			return v, fmt.Errorf("invalid prefix modifier in URI template variable %q", spec)
+		}
+		v.name, v.maxLength = name, maxLength
+	}
+
+	if !isVarName(v.name) {
+		return v, fmt.Errorf("invalid URI template variable %q", spec)
+	}
+	return v, nil
+}
+
+// templateValue converts a parameter into a template value.
+// It returns nil for undefined values: empty lists and empty associative arrays.
+func (e *queryEncoder) templateValue(raw interface{}) (*templateValue, error) {
+	v, tag := reflect.ValueOf(raw), queryTag{}
+	if param, ok := raw.(taggedParam); ok {
+		v, tag = param.value, param.tag
+	}
+	v, ok := indirectValue(v)
+	if !ok {
+		return nil, nil
+	}
+
+	s, ok, err := e.scalar(v, tag)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &templateValue{scalar: &s}, nil
+	}
+
+	value := &templateValue{pairs: make(map[string]string)}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			item, ok := indirectValue(v.Index(i))
+			if !ok {
+				continue
+			}
+			s, err := e.templateScalar(item, tag)
+			if err != nil {
+				return nil, err
+			}
+			value.list = append(value.list, s)
+		}
+		if len(value.list) == 0 {
+			return nil, nil
+		}
+		return value, nil
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			item, ok := indirectValue(v.MapIndex(key))
+			if !ok {
+				continue
+			}
+			s, err := e.templateScalar(item, tag)
+			if err != nil {
+				return nil, err
+			}
+			value.pairs[fmt.Sprintf("%v", key.Interface())] = s
+		}
+	case reflect.Struct:
+		err := walkFields(v, func(name string, field reflect.Value, fieldTag queryTag) error {
+			item, ok := indirectValue(field)
+			if !ok {
+				return nil
+			}
+			s, err := e.templateScalar(item, fieldTag)
+			value.pairs[name] = s
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	if len(value.pairs) == 0 {
+		return nil, nil
+	}
+	for key := range value.pairs {
+		value.keys = append(value.keys, key)
+	}
+	sort.Strings(value.keys)
+	return value, nil
+}
+
+// templateScalar formats a member of a list or associative array, which cannot be nested.
+func (e *queryEncoder) templateScalar(v reflect.Value, tag queryTag) (string, error) {
+	s, ok, err := e.scalar(v, tag)
+	if err == nil && !ok {
+		err = fmt.Errorf("nested value of type %s", v.Type())
+	}
+	return s, err
+}
+
+// truncateRunes returns the first n characters of s.
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// isVarName reports whether name is a valid RFC 6570 variable name,
+// made of letters, digits, underscores, percent-encoded triplets and inner dots.
+func isVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || !utf8.ValidString(name) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '%':
+			if i+2 >= len(name) || !isHex(name[i+1]) || !isHex(name[i+2]) {
+				return false
+			}
+			i += 2
+		case !isNameChar(c, true):
+			return false
+		}
+	}
+	return true
+}
+
+// isUnreserved reports whether c is an RFC 3986 unreserved character.
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
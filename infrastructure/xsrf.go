@@ -0,0 +1,251 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+)
+
+// SetXSRFOptions enables copying a CSRF token from a cookie into a request
+// header on unsafe methods (POST, PUT, PATCH, DELETE, ...). The cookie is read
+// from the client's cookie jar and, in the browser, from document.cookie for
+// same-origin requests. The token is only sent with relative request URLs and
+// URLs with the origin of the base URL or one of Origins; without either,
+// unsafe requests to absolute URLs fail. If the server rejects the token with
+// one of the refresh statuses, the token is refreshed and the request is sent
+// once more. Nil disables XSRF handling. Instances created with NewInstance
+// share the token.
+//
+// Example:
+//
+//	client.SetCookieJar(infrastructure.NewCookieJar()).
+//	    SetXSRFOptions(&models.XSRFOptions{
+//	        CookieName:    "csrftoken",
+//	        HeaderName:    "X-CSRFToken",
+//	        BootstrapPath: "/api/csrf",
+//	    })
+func (c *Client) SetXSRFOptions(options *models.XSRFOptions) *Client {
+	if options == nil {
+		c.xsrf = nil
+		return c
+	}
+	c.xsrf = newXSRFGuard(options)
+	return c
+}
+
+// xsrfGuard applies and refreshes the XSRF token of a client.
+type xsrfGuard struct {
+	cookieName    string
+	headerName    string
+	bootstrapPath string
+	refreshOn     []int
+	origins       []string
+
+	// fetching serializes bootstrap requests so concurrent requests share one refresh
+	fetching sync.Mutex
+
+	mu     sync.Mutex
+	token  string
+	origin string
+}
+
+// newXSRFGuard creates a guard, applying defaults for unset options.
+func newXSRFGuard(options *models.XSRFOptions) *xsrfGuard {
+	x := &xsrfGuard{
+		cookieName:    options.CookieName,
+		headerName:    options.HeaderName,
+		bootstrapPath: options.BootstrapPath,
+		refreshOn:     options.RefreshStatusCodes,
+		origins:       options.Origins,
+	}
+	if x.cookieName == "" {
+		x.cookieName = "XSRF-TOKEN"
+	}
+	if x.headerName == "" {
+		x.headerName = "X-XSRF-TOKEN"
+	}
+	if len(x.refreshOn) == 0 {
+		x.refreshOn = []int{http.StatusForbidden, 419}
+	}
+	return x
+}
+
+// apply sets the token header on an unsafe request to the base URL's origin
+// or one of the configured origins that does not already have one,
+// bootstrapping the token if needed. It reports whether the header was set.
+func (x *xsrfGuard) apply(ctx context.Context, c *Client, req *http.Request, baseURL string) (bool, error) {
+	if isSafeMethod(req.Method) || req.Header.Get(x.headerName) != "" {
+		return false, nil
+	}
+	allowed, err := allowedOrigin(x.origins, baseURL, req.URL)
+	if err != nil {
+		return false, fmt.Errorf("xsrf: %w", err)
+	}
+	if !allowed {
+		return false, nil
+	}
+
+	token := x.currentToken(c, req.URL)
+	if token == "" && x.bootstrapPath != "" {
+		var err error
+		if token, err = x.bootstrap(ctx, c, req.URL, ""); err != nil {
+			return false, err
+		}
+	}
+	if token == "" {
+		return false, nil
+	}
+
+	req.Header.Set(x.headerName, token)
+	return true, nil
+}
+
+// resend refreshes the token after the server rejected it and sends the
+// request once more. The original response is returned if the token cannot
+// be refreshed or the body cannot be replayed.
+func (x *xsrfGuard) resend(ctx context.Context, c *Client, req *http.Request, resp *http.Response, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if !slices.Contains(x.refreshOn, resp.StatusCode) {
+		return resp, nil
+	}
+	if !canReplay(req) {
+		return resp, nil
+	}
+
+	stale := req.Header.Get(x.headerName)
+	token := x.currentToken(c, req.URL)
+	if token == stale && x.bootstrapPath != "" {
+		var err error
+		if token, err = x.bootstrap(ctx, c, req.URL, stale); err != nil {
+			return resp, nil
+		}
+	}
+	if token == "" || token == stale {
+		return resp, nil
+	}
+
+	retry, err := replayRequest(ctx, req)
+	if err != nil {
+		return resp, nil
+	}
+	retry.Header.Set(x.headerName, token)
+
+	discardResponse(resp)
+	return send(retry)
+}
+
+// currentToken returns the token for a request URL: a token received in the
+// bootstrap response header for the same origin, or the token cookie.
+func (x *xsrfGuard) currentToken(c *Client, target *url.URL) string {
+	x.mu.Lock()
+	token, origin := x.token, x.origin
+	x.mu.Unlock()
+	if token != "" && sameOrigin(origin, target) {
+		return token
+	}
+
+	if jar := c.httpClient.Jar; jar != nil {
+		for _, cookie := range jar.Cookies(target) {
+			if cookie.Name == x.cookieName {
+				return cookie.Value
+			}
+		}
+	}
+	return browserCookie(x.cookieName, target)
+}
+
+// bootstrap requests the bootstrap path to obtain a token that differs from
+// stale. Concurrent callers wait for a single request and share its token.
+func (x *xsrfGuard) bootstrap(ctx context.Context, c *Client, target *url.URL, stale string) (string, error) {
+	x.fetching.Lock()
+	defer x.fetching.Unlock()
+
+	// Another request may have refreshed the token in the meantime
+	if token := x.currentToken(c, target); token != "" && token != stale {
+		return token, nil
+	}
+
+	req := c.R().Path(x.bootstrapPath)
+	bootstrapURL, err := req.URL()
+	if err != nil {
+		return "", fmt.Errorf("xsrf bootstrap: %w", err)
+	}
+	resp, err := req.DoStream(ctx)
+	if err != nil {
+		return "", fmt.Errorf("xsrf bootstrap: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	x.mu.Lock()
+	if token := resp.Headers.Get(x.headerName); token != "" {
+		x.token, x.origin = token, originOf(bootstrapURL)
+	} else {
+		// The header token is stale too; rely on the refreshed cookie
+		x.token, x.origin = "", ""
+	}
+	x.mu.Unlock()
+
+	return x.currentToken(c, target), nil
+}
+
+// isSafeMethod reports whether a method is safe as defined by RFC 9110, in
+// which case no XSRF token is needed.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// originOf returns the scheme://host origin of a URL, or "" for a relative URL.
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// errNoOrigin is returned when credentials are scoped to the base URL's
+// origin, but the client has neither a base URL nor configured origins.
+var errNoOrigin = fmt.Errorf("no origin to send credentials to, set a base URL or Origins")
+
+// allowedOrigin reports whether credentials may be sent to target: relative
+// URLs and URLs with the origin of the base URL or one of origins are
+// allowed. It fails for absolute URLs if there is no origin to compare with,
+// rather than silently sending requests without credentials.
+func allowedOrigin(origins []string, baseURL string, target *url.URL) (bool, error) {
+	if target.Host == "" {
+		return true, nil
+	}
+	base := originOf(baseURL)
+	if base == "" && len(origins) == 0 {
+		return false, errNoOrigin
+	}
+	if sameOrigin(base, target) {
+		return true, nil
+	}
+	for _, origin := range origins {
+		if sameOrigin(originOf(origin), target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sameOrigin reports whether target has the given origin. Relative URLs,
+// resolved against the page in the browser, always match, but an absolute URL
+// never matches a relative origin.
+func sameOrigin(origin string, target *url.URL) bool {
+	if target.Host == "" {
+		return true
+	}
+	return origin != "" && origin == target.Scheme+"://"+target.Host
+}
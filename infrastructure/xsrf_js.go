@@ -0,0 +1,36 @@
+//go:build js && wasm
+// +build js,wasm
+
+package infrastructure
+
+import (
+	"net/url"
+	"strings"
+	"syscall/js"
+)
+
+// browserCookie reads a cookie from document.cookie. The cookie is only
+// returned for requests to the page's own origin, so the token is never
+// leaked to third-party hosts.
+func browserCookie(name string, target *url.URL) string {
+	document := js.Global().Get("document")
+	location := js.Global().Get("location")
+	if document.IsUndefined() || location.IsUndefined() {
+		return ""
+	}
+	if !sameOrigin(location.Get("origin").String(), target) {
+		return ""
+	}
+
+	for _, pair := range strings.Split(document.Get("cookie").String(), ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || key != name {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			return unescaped
+		}
+		return value
+	}
+	return ""
+}
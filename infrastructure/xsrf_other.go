@@ -0,0 +1,11 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package infrastructure
+
+import "net/url"
+
+// browserCookie returns "" outside the browser, where there is no document.cookie.
+func browserCookie(name string, target *url.URL) string {
+	return ""
+}
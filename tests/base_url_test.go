@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestBaseURLResolution(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		path     string
+		expected string
+	}{
+		{"https://api.example.com/v1", "/users", "https://api.example.com/v1/users"},
+		{"https://api.example.com/v1/", "users", "https://api.example.com/v1/users"},
+		{"https://api.example.com/v1", "", "https://api.example.com/v1"},
+		{"https://api.example.com/v1/", "../v2/users", "https://api.example.com/v2/users"},
+		{"https://api.example.com/v1", "./users/./list", "https://api.example.com/v1/users/list"},
+		{"https://api.example.com/v1", "/v1/items:batchGet", "https://api.example.com/v1/v1/items:batchGet"},
+		{"https://api.example.com/v1", "items:batchGet", "https://api.example.com/v1/items:batchGet"},
+		{"https://api.example.com/v1?key=abc", "/users", "https://api.example.com/v1/users?key=abc"},
+		{"https://api.example.com/v1?key=abc", "/users?page=2", "https://api.example.com/v1/users?key=abc&page=2"},
+		{"https://api.example.com/v1?key=abc", "", "https://api.example.com/v1?key=abc"},
+		{"https://api.example.com/v1", "/docs#intro", "https://api.example.com/v1/docs#intro"},
+		{"https://api.example.com/v1", "https://cdn.example.com/file", "https://cdn.example.com/file"},
+		{"https://api.example.com/v1", "//cdn.example.com/file", "https://cdn.example.com/file"},
+		{"", "https://api.example.com/users", "https://api.example.com/users"},
+		{"", "/users", "/users"},
+	}
+
+	for _, tt := range tests {
+		client := infrastructure.NewClient().SetBaseURL(tt.baseURL)
+		fullURL, err := client.R().Path(tt.path).URL()
+		if err != nil {
+			t.Errorf("%s + %s: expected no error, got %v", tt.baseURL, tt.path, err)
+			continue
+		}
+		if fullURL != tt.expected {
+			t.Errorf("%s + %s: expected %s, got %s", tt.baseURL, tt.path, tt.expected, fullURL)
+		}
+	}
+}
+
+func TestBaseURLQueryMergedWithParams(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL + "/api?key=abc")
+	_, err := client.Get(context.Background(), "/users?active=true", map[string]interface{}{"page": 2}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if rawQuery != "key=abc&active=true&page=2" {
+		t.Errorf("Expected all query strings to be merged, got %s", rawQuery)
+	}
+}
+
+func TestDenyAbsoluteURLs(t *testing.T) {
+	client := infrastructure.NewClient().
+		SetBaseURL("https://api.example.com").
+		SetAllowAbsoluteURLs(false)
+
+	for _, path := range []string{"https://evil.example.com/steal", "//evil.example.com/steal"} {
+		_, err := client.R().Path(path).URL()
+		if err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("%s: expected absolute URL to be denied, got %v", path, err)
+		}
+	}
+
+	// Relative paths are unaffected
+	if fullURL, err := client.R().Path("/users").URL(); err != nil || fullURL != "https://api.example.com/users" {
+		t.Errorf("Expected relative path to resolve, got %s %v", fullURL, err)
+	}
+
+	// A per-request override allows a trusted link
+	allow := true
+	fullURL, err := client.R().
+		Path("https://cdn.example.com/file").
+		Config(&models.Config{AllowAbsoluteURLs: &allow}).
+		URL()
+	if err != nil || fullURL != "https://cdn.example.com/file" {
+		t.Errorf("Expected per-request override to allow the URL, got %s %v", fullURL, err)
+	}
+}
+
+func TestAbsoluteURLOverridesBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL("http://127.0.0.1:1/unreachable")
+	resp, err := client.Get(context.Background(), server.URL+"/ping", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+}
+
+func TestSchemeRelativeURLTakesBaseScheme(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL("http://127.0.0.1:1/unreachable")
+	schemeRelative := "//" + strings.TrimPrefix(server.URL, "http://") + "/ping"
+
+	fullURL, err := client.R().Path(schemeRelative).URL()
+	if err != nil || fullURL != server.URL+"/ping" {
+		t.Errorf("Expected the base URL scheme, got %s %v", fullURL, err)
+	}
+
+	resp, err := client.Get(context.Background(), schemeRelative, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent || path != "/ping" {
+		t.Errorf("Expected the request to reach /ping, got %d %s", resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/infrastructure"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func charsetServer(contentType string, body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+}
+
+func TestCharsetLatin1IsTranscoded(t *testing.T) {
+	// "José Müller" in ISO-8859-1
+	body := []byte("{\"id\":1,\"name\":\"Jos\xe9 M\xfcller\"}")
+	server := charsetServer("application/json; charset=ISO-8859-1", body)
+	defer server.Close()
+
+	var transformed string
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetDataTransformer(func(data []byte) ([]byte, error) {
+			transformed = string(data)
+			return data, nil
+		})
+
+	var user TestUser
+	resp, err := client.Get(context.Background(), "/users/1", nil, &user)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if user.Name != "José Müller" {
+		t.Errorf("Expected transcoded name, got %q", user.Name)
+	}
+	if transformed != `{"id":1,"name":"José Müller"}` {
+		t.Errorf("Expected transformer to receive UTF-8, got %q", transformed)
+	}
+	if string(resp.RawBody) != string(body) {
+		t.Errorf("Expected RawBody to be kept as received, got %q", resp.RawBody)
+	}
+}
+
+func TestCharsetShiftJISIsTranscoded(t *testing.T) {
+	body, _ := japanese.ShiftJIS.NewEncoder().Bytes([]byte(`{"id":2,"name":"山田太郎"}`))
+	server := charsetServer("application/json; charset=Shift_JIS", body)
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var user TestUser
+	if _, err := client.Get(context.Background(), "/users/2", nil, &user); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Name != "山田太郎" {
+		t.Errorf("Expected transcoded name, got %q", user.Name)
+	}
+}
+
+func TestCharsetByteOrderMarkIsSniffed(t *testing.T) {
+	utf16, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(`{"id":3,"name":"Zoë"}`))
+	withUTF8BOM := append([]byte("\xef\xbb\xbf"), `{"id":4,"name":"Zoë"}`...)
+
+	for name, body := range map[string][]byte{"utf-16le": utf16, "utf-8": withUTF8BOM} {
+		t.Run(name, func(t *testing.T) {
+			server := charsetServer("application/json", body)
+			defer server.Close()
+
+			client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+			var user TestUser
+			if _, err := client.Get(context.Background(), "/users", nil, &user); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if user.Name != "Zoë" {
+				t.Errorf("Expected BOM-detected text, got %q", user.Name)
+			}
+		})
+	}
+}
+
+func TestCharsetXMLDeclarationIsHandled(t *testing.T) {
+	type city struct {
+		XMLName xml.Name `xml:"city"`
+		Name    string   `xml:"name"`
+	}
+
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><city><name>K\xf6ln</name></city>")
+
+	for name, contentType := range map[string]string{
+		"header":   "application/xml; charset=ISO-8859-1",
+		"prolog":   "application/xml",
+		"override": "application/xml; charset=windows-1252",
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := charsetServer(contentType, body)
+			defer server.Close()
+
+			client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+			var result city
+			if _, err := client.Get(context.Background(), "/city", nil, &result); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Name != "Köln" {
+				t.Errorf("Expected transcoded name, got %q", result.Name)
+			}
+		})
+	}
+}
+
+func TestCharsetBinaryBodiesAreUntouched(t *testing.T) {
+	body := []byte{0xFF, 0xFE, 0x00, 0x01}
+	server := charsetServer("application/octet-stream", body)
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var data []byte
+	if _, err := client.Get(context.Background(), "/blob", nil, &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(data) != string(body) {
+		t.Errorf("Expected binary body to be unchanged, got %v", data)
+	}
+}
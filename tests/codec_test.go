@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+type xmlUser struct {
+	XMLName xml.Name `xml:"user"`
+	ID      int      `xml:"id"`
+	Name    string   `xml:"name"`
+}
+
+func TestXMLCodecRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/xml" {
+			t.Errorf("Expected XML content type, got %q", r.Header.Get("Content-Type"))
+		}
+
+		var user xmlUser
+		if err := xml.NewDecoder(r.Body).Decode(&user); err != nil {
+			t.Errorf("Expected XML body, got error: %v", err)
+		}
+		user.ID = 5
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		xml.NewEncoder(w).Encode(user)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetHeader("Content-Type", "application/xml")
+
+	var result xmlUser
+	_, err := client.Post(context.Background(), "/users", nil, xmlUser{Name: "Ada"}, &result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != 5 || result.Name != "Ada" {
+		t.Errorf("Expected decoded XML user, got %+v", result)
+	}
+}
+
+func TestFormCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Expected form body, got error: %v", err)
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" {
+			t.Errorf("Expected grant_type form field, got %q", r.PostForm.Get("grant_type"))
+		}
+
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=abc&token_type=bearer"))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var token map[string]string
+	_, err := client.Post(context.Background(), "/token", nil,
+		url.Values{"grant_type": {"client_credentials"}},
+		&token,
+		&models.Config{Headers: map[string]string{"Content-Type": infrastructure.ContentTypeForm}},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if token["access_token"] != "abc" {
+		t.Errorf("Expected decoded form response, got %v", token)
+	}
+}
+
+func TestTextAndBytesCodecs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var text string
+	_, err := client.R().
+		Method(http.MethodPost).
+		Header("Content-Type", "text/plain; charset=utf-8").
+		Body("hello").
+		Into(&text).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text != "hello" {
+		t.Errorf("Expected text body, got %q", text)
+	}
+
+	var raw []byte
+	_, err = client.R().
+		Method(http.MethodPost).
+		Header("Content-Type", infrastructure.ContentTypeBytes).
+		Body([]byte{0x00, 0x01, 0x02}).
+		Into(&raw).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(raw) != 3 || raw[2] != 0x02 {
+		t.Errorf("Expected raw bytes, got %v", raw)
+	}
+}
+
+func TestAcceptHeaderAndSuffixLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept")
+		if !strings.HasPrefix(accept, "application/json, application/xml") {
+			t.Errorf("Expected Accept derived from codecs, got %q", accept)
+		}
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.Write([]byte(`{"title":"ok"}`))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var problem map[string]string
+	if _, err := client.Get(context.Background(), "/", nil, &problem); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if problem["title"] != "ok" {
+		t.Errorf("Expected +json suffix to use JSON codec, got %v", problem)
+	}
+}
+
+type upperCodec struct{}
+
+func (upperCodec) ContentType() string { return "text/x-upper" }
+
+func (upperCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(v.(string))), nil
+}
+
+func (upperCodec) Decode(data []byte, target interface{}) error {
+	*target.(*string) = strings.ToLower(string(data))
+	return nil
+}
+
+func TestCustomCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "SHOUT" {
+			t.Errorf("Expected custom encoding, got %q", string(body))
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "text/x-upper") {
+			t.Errorf("Expected custom codec in Accept, got %q", r.Header.Get("Accept"))
+		}
+
+		w.Header().Set("Content-Type", "text/x-upper")
+		w.Write([]byte("WHISPER"))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCodec(upperCodec{})
+
+	var result string
+	_, err := client.Post(context.Background(), "/", nil, "shout", &result,
+		&models.Config{Headers: map[string]string{"Content-Type": "text/x-upper"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != "whisper" {
+		t.Errorf("Expected custom decoding, got %q", result)
+	}
+
+	// Unknown request content types are rejected instead of silently sent as JSON
+	_, err = infrastructure.NewClient().SetBaseURL(server.URL).Post(context.Background(), "/", nil, "shout", nil,
+		&models.Config{Headers: map[string]string{"Content-Type": "text/x-upper"}})
+	if err == nil {
+		t.Error("Expected error for unregistered content type")
+	}
+}
+
+func TestOctetStreamDecodesStructTargetsAsJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", infrastructure.ContentTypeBytes)
+		w.Write([]byte(`{"name":"gofetch"}`))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	if _, err := client.Get(context.Background(), "/", nil, &result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Name != "gofetch" {
+		t.Errorf("Expected JSON fallback for octet-stream, got %+v", result)
+	}
+}
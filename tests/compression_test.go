@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+	"github.com/klauspost/compress/zstd"
+)
+
+func decodeTestBody(t *testing.T, encoding string, r io.Reader) []byte {
+	t.Helper()
+
+	var reader io.Reader
+	switch encoding {
+	case "":
+		reader = r
+	case "gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("Invalid gzip body: %v", err)
+		}
+		reader = gz
+	case "zstd":
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatalf("Invalid zstd body: %v", err)
+		}
+		defer dec.Close()
+		reader = dec
+	case "br":
+		reader = brotli.NewReader(r)
+	default:
+		t.Fatalf("Unexpected encoding %q", encoding)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decode %s body: %v", encoding, err)
+	}
+	return data
+}
+
+func encodeTestBody(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "zstd":
+		enc, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer = enc
+	case "br":
+		writer = brotli.NewWriter(&buf)
+	}
+	writer.Write(data)
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestRequestCompression(t *testing.T) {
+	for _, encoding := range []string{infrastructure.EncodingGzip, infrastructure.EncodingZstd, infrastructure.EncodingBrotli} {
+		t.Run(encoding, func(t *testing.T) {
+			var received TestUser
+			var contentEncoding string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentEncoding = r.Header.Get("Content-Encoding")
+				json.Unmarshal(decodeTestBody(t, contentEncoding, r.Body), &received)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			client := infrastructure.NewClient().
+				SetBaseURL(server.URL).
+				SetCompressionOptions(&models.CompressionOptions{Encoding: encoding, MinSize: 32})
+
+			user := TestUser{ID: 1, Name: strings.Repeat("John Doe ", 10), Email: "john@example.com"}
+			if _, err := client.Post(context.Background(), "/users", nil, user, nil); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if contentEncoding != encoding || received != user {
+				t.Errorf("Expected %s body %+v, got %q %+v", encoding, user, contentEncoding, received)
+			}
+
+			// Bodies below the threshold are sent as is
+			small := TestUser{ID: 2}
+			if _, err := client.Post(context.Background(), "/users", nil, small, nil); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if contentEncoding != "" || received.ID != 2 {
+				t.Errorf("Expected small body to be uncompressed, got %q", contentEncoding)
+			}
+		})
+	}
+}
+
+func TestRequestCompressionStreamsReaders(t *testing.T) {
+	content := strings.Repeat("log line\n", 1000)
+	var received []byte
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		received = decodeTestBody(t, r.Header.Get("Content-Encoding"), r.Body)
+	}))
+	defer server.Close()
+
+	var uploaded [2]int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCompressionOptions(&models.CompressionOptions{Encoding: infrastructure.EncodingZstd}).
+		SetUploadProgress(func(sent, total int64) {
+			uploaded = [2]int64{sent, total}
+		})
+
+	if _, err := client.Post(context.Background(), "/logs", nil, strings.NewReader(content), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(received) != content || contentLength != -1 {
+		t.Errorf("Expected chunked compressed body, got %d bytes with length %d", len(received), contentLength)
+	}
+	if uploaded[0] != int64(len(content)) || uploaded[1] != int64(len(content)) {
+		t.Errorf("Expected upload progress in uncompressed bytes, got %v", uploaded)
+	}
+}
+
+func TestResponseDecompression(t *testing.T) {
+	user := TestUser{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	payload, _ := json.Marshal(user)
+
+	for _, encoding := range []string{"gzip", "zstd", "br"} {
+		t.Run(encoding, func(t *testing.T) {
+			var acceptEncoding string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", encoding)
+				w.Write(encodeTestBody(t, encoding, payload))
+			}))
+			defer server.Close()
+
+			client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+			var result TestUser
+			resp, err := client.Get(context.Background(), "/users/1", nil, &result)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if result != user || !bytes.Equal(resp.RawBody, payload) {
+				t.Errorf("Expected decoded body, got %+v %q", result, resp.RawBody)
+			}
+			if resp.Headers.Get("Content-Encoding") != "" {
+				t.Error("Expected Content-Encoding to be removed after decoding")
+			}
+			if acceptEncoding != "gzip, br, zstd" {
+				t.Errorf("Expected Accept-Encoding to advertise codings, got %q", acceptEncoding)
+			}
+		})
+	}
+}
+
+func TestResponseDecompressionProgress(t *testing.T) {
+	payload := bytes.Repeat([]byte("compressible "), 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := encodeTestBody(t, "br", payload)
+		w.Header().Set("Content-Encoding", "br")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	var download [2]int64
+	var transfer [3]int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetDownloadProgress(func(loaded, total int64) {
+			download = [2]int64{loaded, total}
+		}).
+		SetTransferProgress(func(wire, decoded, wireTotal int64) {
+			transfer = [3]int64{wire, decoded, wireTotal}
+		})
+
+	resp, err := client.GetStream(context.Background(), "/export", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !bytes.Equal(data, payload) || !resp.Uncompressed {
+		t.Fatalf("Expected decoded stream, got %d bytes", len(data))
+	}
+
+	wireSize := int64(len(encodeTestBody(t, "br", payload)))
+	if download[0] != wireSize || download[1] != wireSize {
+		t.Errorf("Expected download progress in wire bytes, got %v of %d", download, wireSize)
+	}
+	if transfer[0] != wireSize || transfer[1] != int64(len(payload)) || transfer[2] != wireSize {
+		t.Errorf("Expected wire and decoded bytes, got %v", transfer)
+	}
+}
+
+func TestExplicitAcceptEncodingKeepsBody(t *testing.T) {
+	compressed := encodeTestBody(t, "gzip", []byte("raw"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetHeader("Accept-Encoding", "gzip")
+
+	resp, err := client.Get(context.Background(), "/raw", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(resp.RawBody, compressed) || resp.Headers.Get("Content-Encoding") != "gzip" {
+		t.Error("Expected the compressed body to be returned as sent")
+	}
+}
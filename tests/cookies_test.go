@@ -0,0 +1,254 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// newSessionServer starts a server that sets a session cookie on /login and
+// requires it on /me.
+func newSessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/", HttpOnly: true})
+			w.WriteHeader(http.StatusNoContent)
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "session", Path: "/", MaxAge: -1})
+			w.WriteHeader(http.StatusNoContent)
+		case "/me":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id":1,"name":"Jane"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("Expected valid URL, got %v", err)
+	}
+	return u
+}
+
+func TestCookieJarKeepsSession(t *testing.T) {
+	server := newSessionServer(t)
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(infrastructure.NewCookieJar())
+
+	if _, err := client.Get(context.Background(), "/me", nil, nil); err == nil {
+		t.Fatal("Expected request without session to fail")
+	}
+
+	if _, err := client.Get(context.Background(), "/login", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var user TestUser
+	if _, err := client.Get(context.Background(), "/me", nil, &user); err != nil {
+		t.Fatalf("Expected session cookie to be sent, got %v", err)
+	}
+	if user.Name != "Jane" {
+		t.Errorf("Expected user Jane, got %+v", user)
+	}
+
+	cookies, err := client.Cookies("/me")
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "abc123" {
+		t.Errorf("Expected session cookie to be inspectable, got %v %v", cookies, err)
+	}
+
+	// Max-Age=-1 removes the cookie
+	if _, err := client.Get(context.Background(), "/logout", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cookies, _ := client.Cookies("/me"); len(cookies) != 0 {
+		t.Errorf("Expected cookie to be removed, got %v", cookies)
+	}
+}
+
+func TestCookieJarSharedWithNewInstance(t *testing.T) {
+	server := newSessionServer(t)
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(infrastructure.NewCookieJar())
+
+	derived := client.NewInstance()
+	if derived.GetCookieJar() != client.GetCookieJar() {
+		t.Fatal("Expected derived client to share the cookie jar")
+	}
+
+	if _, err := client.Get(context.Background(), "/login", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := derived.Get(context.Background(), "/me", nil, nil); err != nil {
+		t.Errorf("Expected derived client to send the session cookie, got %v", err)
+	}
+
+	client.ClearCookies()
+	if _, err := derived.Get(context.Background(), "/me", nil, nil); err == nil {
+		t.Error("Expected cleared session to be rejected")
+	}
+}
+
+func TestCookieJarDomainRules(t *testing.T) {
+	jar := infrastructure.NewCookieJar()
+	origin := mustParseURL(t, "https://www.example.co.uk/account/settings")
+
+	jar.SetCookies(origin, []*http.Cookie{
+		{Name: "suffix", Value: "1", Domain: "co.uk"},
+		{Name: "foreign", Value: "1", Domain: "other.co.uk"},
+		{Name: "shared", Value: "1", Domain: ".example.co.uk", Path: "/"},
+		{Name: "host", Value: "1"},
+		{Name: "secure", Value: "1", Path: "/", Secure: true},
+	})
+
+	names := func(raw string) string {
+		var result []string
+		for _, cookie := range jar.Cookies(mustParseURL(t, raw)) {
+			result = append(result, cookie.Name)
+		}
+		return strings.Join(result, ",")
+	}
+
+	// Host-only cookies default to the directory of the request path
+	if got := names("https://www.example.co.uk/account/profile"); got != "host,shared,secure" {
+		t.Errorf("Expected host,shared,secure on the origin, got %s", got)
+	}
+	if got := names("https://shop.example.co.uk/"); got != "shared" {
+		t.Errorf("Expected only the domain cookie on a sibling host, got %s", got)
+	}
+	if got := names("http://www.example.co.uk/"); got != "shared" {
+		t.Errorf("Expected secure cookie to be withheld over http, got %s", got)
+	}
+	if got := names("https://evil.co.uk/"); got != "" {
+		t.Errorf("Expected public suffix cookies to be rejected, got %s", got)
+	}
+
+	if len(jar.All()) != 3 {
+		t.Errorf("Expected 3 stored cookies, got %v", jar.All())
+	}
+	if !jar.Delete("example.co.uk", "/", "shared") || jar.Delete("example.co.uk", "/", "shared") {
+		t.Error("Expected the cookie to be deleted once")
+	}
+}
+
+func TestCookieJarExpiry(t *testing.T) {
+	jar := infrastructure.NewCookieJar()
+	origin := mustParseURL(t, "https://example.com/")
+
+	jar.SetCookies(origin, []*http.Cookie{
+		{Name: "short", Value: "1", Expires: time.Now().Add(50 * time.Millisecond)},
+		{Name: "past", Value: "1", Expires: time.Now().Add(-time.Hour)},
+		{Name: "long", Value: "1", MaxAge: 3600},
+	})
+
+	if cookies := jar.Cookies(origin); len(cookies) != 2 {
+		t.Fatalf("Expected 2 cookies, got %v", cookies)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	cookies := jar.Cookies(origin)
+	if len(cookies) != 1 || cookies[0].Name != "long" {
+		t.Errorf("Expected only the long-lived cookie, got %v", cookies)
+	}
+}
+
+func TestPersistentCookieJar(t *testing.T) {
+	for _, format := range []infrastructure.CookieFileFormat{infrastructure.CookieFileJSON, infrastructure.CookieFileNetscape} {
+		t.Run(string(format), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "cookies")
+			jar, err := infrastructure.NewPersistentCookieJar(filename, format)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			expires := time.Now().Add(time.Hour).Truncate(time.Second)
+			jar.SetCookies(mustParseURL(t, "https://api.example.com/"), []*http.Cookie{
+				{Name: "session", Value: "abc", Path: "/", HttpOnly: true},
+				{Name: "prefs", Value: "dark", Domain: "example.com", Path: "/", Expires: expires, Secure: true},
+			})
+
+			reloaded, err := infrastructure.NewPersistentCookieJar(filename, format)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			cookies := reloaded.All()
+			if len(cookies) != 2 {
+				t.Fatalf("Expected 2 cookies after reload, got %v", cookies)
+			}
+			if cookies[0].Name != "session" || !cookies[0].HttpOnly || !cookies[0].Expires.IsZero() {
+				t.Errorf("Expected session cookie to be restored, got %+v", cookies[0])
+			}
+			if cookies[1].Name != "prefs" || !cookies[1].Secure || !cookies[1].Expires.Equal(expires) {
+				t.Errorf("Expected persistent cookie to be restored, got %+v", cookies[1])
+			}
+
+			// The domain cookie still applies to subdomains, the host-only cookie does not
+			if got := reloaded.Cookies(mustParseURL(t, "https://www.example.com/")); len(got) != 1 || got[0].Name != "prefs" {
+				t.Errorf("Expected only the domain cookie on a subdomain, got %v", got)
+			}
+
+			reloaded.Clear()
+			if empty, _ := infrastructure.NewPersistentCookieJar(filename, format); len(empty.All()) != 0 {
+				t.Error("Expected cleared jar to be saved")
+			}
+		})
+	}
+}
+
+func TestNetscapeCookieFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cookies.txt")
+	content := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tshared\tone\n" +
+		"#HttpOnly_api.example.com\tFALSE\t/v1\tTRUE\t4102444800\ttoken\ttwo\n" +
+		"old.example.com\tFALSE\t/\tFALSE\t1\texpired\tthree\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	jar, err := infrastructure.NewPersistentCookieJar(filename, infrastructure.CookieFileNetscape)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cookies := jar.Cookies(mustParseURL(t, "https://api.example.com/v1/users"))
+	if len(cookies) != 2 || cookies[0].Name != "token" || cookies[1].Name != "shared" {
+		t.Errorf("Expected token and shared cookies, got %v", cookies)
+	}
+
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	saved, _ := os.ReadFile(filename)
+	if !strings.Contains(string(saved), "#HttpOnly_api.example.com\tFALSE\t/v1\tTRUE\t4102444800\ttoken\ttwo") {
+		t.Errorf("Expected HttpOnly cookie to be written back, got:\n%s", saved)
+	}
+	if strings.Contains(string(saved), "expired") {
+		t.Error("Expected expired cookie to be dropped")
+	}
+
+	if err := os.WriteFile(filename, []byte("broken line\n"), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := infrastructure.NewPersistentCookieJar(filename, infrastructure.CookieFileNetscape); err == nil {
+		t.Error("Expected an error for a malformed cookie file")
+	}
+}
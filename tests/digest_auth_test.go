@@ -0,0 +1,359 @@
+package tests
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// digestServer is a Digest authentication server (RFC 7616) for user
+// "admin" with password "secret".
+type digestServer struct {
+	*httptest.Server
+	algorithms []string
+	qop        string
+
+	mu         sync.Mutex
+	nonce      int
+	requests   int
+	challenges int
+	counts     []string
+	cnonces    []string
+	bodies     []string
+	failNext   bool
+}
+
+var digestParamPattern = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^\s,]+))`)
+
+func newDigestServer(t *testing.T, qop string, algorithms ...string) *digestServer {
+	t.Helper()
+	ds := &digestServer{algorithms: algorithms, qop: qop, nonce: 1}
+	ds.Server = httptest.NewServer(http.HandlerFunc(ds.handle))
+	t.Cleanup(ds.Close)
+	return ds
+}
+
+func (ds *digestServer) handle(w http.ResponseWriter, r *http.Request) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.requests++
+
+	body, _ := io.ReadAll(r.Body)
+	params := map[string]string{}
+	for _, match := range digestParamPattern.FindAllStringSubmatch(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "), -1) {
+		params[match[1]] = match[2] + match[3]
+	}
+
+	nonce := fmt.Sprintf("nonce-%d", ds.nonce)
+	if params["response"] == "" || params["response"] != ds.expected(params, r.Method, body) {
+		ds.challenge(w, nonce, false)
+		return
+	}
+	if params["nonce"] != nonce {
+		ds.challenge(w, nonce, true)
+		return
+	}
+
+	ds.counts = append(ds.counts, params["nc"])
+	ds.cnonces = append(ds.cnonces, params["cnonce"])
+	ds.bodies = append(ds.bodies, string(body))
+	if ds.failNext {
+		ds.failNext = false
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte(`{"id":1,"name":"Jane"}`))
+}
+
+func (ds *digestServer) challenge(w http.ResponseWriter, nonce string, stale bool) {
+	ds.challenges++
+	for _, algorithm := range ds.algorithms {
+		value := fmt.Sprintf(`Digest realm="appliance", nonce="%s", opaque="xyz", algorithm=%s`, nonce, algorithm)
+		if ds.qop != "" {
+			value += fmt.Sprintf(`, qop="%s"`, ds.qop)
+		}
+		if stale {
+			value += ", stale=true"
+		}
+		w.Header().Add("WWW-Authenticate", value)
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+// expected computes the response the client should have sent.
+func (ds *digestServer) expected(params map[string]string, method string, body []byte) string {
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(params["algorithm"], "-sess") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return ""
+	}
+	h := func(data string) string {
+		sum := newHash()
+		sum.Write([]byte(data))
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	a1 := "admin:" + params["realm"] + ":secret"
+	if strings.HasSuffix(params["algorithm"], "-sess") {
+		a1 = h(a1) + ":" + params["nonce"] + ":" + params["cnonce"]
+	}
+	a2 := method + ":" + params["uri"]
+	if params["qop"] == "auth-int" {
+		a2 += ":" + h(string(body))
+	}
+	if params["qop"] == "" {
+		return h(h(a1) + ":" + params["nonce"] + ":" + h(a2))
+	}
+	return h(h(a1) + ":" + params["nonce"] + ":" + params["nc"] + ":" + params["cnonce"] + ":" + params["qop"] + ":" + h(a2))
+}
+
+func TestDigestAuthCachesChallenge(t *testing.T) {
+	ds := newDigestServer(t, "auth,auth-int", "MD5")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret")
+
+	for i := 0; i < 3; i++ {
+		var user TestUser
+		if _, err := client.Get(context.Background(), "/status", map[string]interface{}{"page": i}, &user); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if user.Name != "Jane" {
+			t.Errorf("Expected user Jane, got %+v", user)
+		}
+	}
+
+	// Only the first request is challenged, later ones count up the nonce
+	if ds.challenges != 1 || ds.requests != 4 {
+		t.Errorf("Expected 1 challenge in 4 requests, got %d in %d", ds.challenges, ds.requests)
+	}
+	if strings.Join(ds.counts, ",") != "00000001,00000002,00000003" {
+		t.Errorf("Expected increasing nonce counts, got %v", ds.counts)
+	}
+}
+
+func TestDigestAuthSessionKeepsClientNonce(t *testing.T) {
+	ds := newDigestServer(t, "auth", "SHA-256-sess")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret")
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), "/status", nil, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// The session key is bound to one cnonce, only the nonce count changes
+	if strings.Join(ds.counts, ",") != "00000001,00000002,00000003" {
+		t.Errorf("Expected increasing nonce counts, got %v", ds.counts)
+	}
+	if len(ds.cnonces) != 3 || ds.cnonces[0] == "" || ds.cnonces[1] != ds.cnonces[0] || ds.cnonces[2] != ds.cnonces[0] {
+		t.Errorf("Expected the cnonce to be reused for the session, got %v", ds.cnonces)
+	}
+}
+
+func TestDigestAuthAlgorithms(t *testing.T) {
+	tests := []struct {
+		name       string
+		qop        string
+		algorithms []string
+	}{
+		{"MD5-sess", "auth", []string{"MD5-sess"}},
+		{"SHA-256", "auth", []string{"SHA-256"}},
+		{"SHA-256-sess", "auth", []string{"SHA-256-sess"}},
+		{"prefers SHA-256", "auth", []string{"MD5", "SHA-256"}},
+		{"auth-int", "auth-int", []string{"SHA-256"}},
+		{"RFC 2069", "", []string{"MD5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := newDigestServer(t, tt.qop, tt.algorithms...)
+			client := infrastructure.NewClient().
+				SetBaseURL(ds.URL).
+				SetDigestAuth("admin", "secret")
+
+			var user TestUser
+			if _, err := client.Post(context.Background(), "/config", nil, TestUser{Name: "Jane"}, &user); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(ds.bodies) != 1 || !strings.Contains(ds.bodies[0], "Jane") {
+				t.Errorf("Expected the body to be replayed, got %v", ds.bodies)
+			}
+		})
+	}
+}
+
+func TestDigestAuthIntMultipartBody(t *testing.T) {
+	ds := newDigestServer(t, "auth-int", "SHA-256")
+	var sent, total int64
+	passes := 0
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret").
+		SetUploadProgress(func(transferred, length int64) {
+			if transferred < sent || passes == 0 {
+				passes++
+			}
+			sent, total = transferred, length
+		})
+
+	form := infrastructure.NewMultipart().
+		Field("name", "Jane").
+		File("avatar", "avatar.png", strings.NewReader(strings.Repeat("x", 1<<20)))
+	if _, err := client.Post(context.Background(), "/upload", nil, form, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The body hash matches the multipart body sent with the credentials
+	if len(ds.bodies) != 1 || !strings.Contains(ds.bodies[0], "avatar.png") {
+		t.Errorf("Expected the multipart body to be replayed, got %d bodies", len(ds.bodies))
+	}
+	// Progress covers the challenged request and its replay, not the hashing
+	if passes != 2 || total != form.Size() || sent != total {
+		t.Errorf("Expected 2 uploads of %d bytes, got %d ending at %d of %d", form.Size(), passes, sent, total)
+	}
+}
+
+func TestDigestAuthIntSeekableReaderBody(t *testing.T) {
+	ds := newDigestServer(t, "auth-int", "SHA-256")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret")
+
+	// The replay and the request answering the cached challenge hash the body before sending it
+	for i := 0; i < 2; i++ {
+		body := &seekOnlyReader{reader: strings.NewReader("raw payload")}
+		if _, err := client.Put(context.Background(), "/config", nil, body, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if len(ds.bodies) != 2 || ds.bodies[0] != "raw payload" || ds.bodies[1] != "raw payload" {
+		t.Errorf("Expected the whole body to be hashed and sent, got %q", ds.bodies)
+	}
+}
+
+func TestDigestAuthStaleNonce(t *testing.T) {
+	ds := newDigestServer(t, "auth", "SHA-256")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret")
+
+	if _, err := client.Get(context.Background(), "/status", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The server rotates its nonce and rejects the cached one as stale
+	ds.mu.Lock()
+	ds.nonce++
+	ds.mu.Unlock()
+	if _, err := client.Get(context.Background(), "/status", nil, nil); err != nil {
+		t.Fatalf("Expected the request to be replayed with the new nonce, got %v", err)
+	}
+	if ds.challenges != 2 || strings.Join(ds.counts, ",") != "00000001,00000001" {
+		t.Errorf("Expected the nonce count to restart, got %d challenges and counts %v", ds.challenges, ds.counts)
+	}
+}
+
+func TestDigestAuthWrongPassword(t *testing.T) {
+	ds := newDigestServer(t, "auth", "MD5")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "wrong")
+
+	_, err := client.Get(context.Background(), "/status", nil, nil)
+	if httpErr, ok := err.(*errors.HTTPError); !ok || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %v", err)
+	}
+	if ds.requests != 2 {
+		t.Errorf("Expected a single replay, got %d requests", ds.requests)
+	}
+}
+
+func TestDigestAuthWithInterceptorsAndRetries(t *testing.T) {
+	ds := newDigestServer(t, "auth", "SHA-256")
+	client := infrastructure.NewClient().
+		SetBaseURL(ds.URL).
+		SetDigestAuth("admin", "secret").
+		SetRetryOptions(&models.RetryOptions{MaxRetries: 2, InitialDelay: time.Millisecond}).
+		AddRequestInterceptor(func(req *http.Request) (*http.Request, error) {
+			// The credentials cover the URI as rewritten by interceptors
+			req.URL.Path = "/v2" + req.URL.Path
+			return req, nil
+		})
+
+	if _, err := client.Get(context.Background(), "/status", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A retried request authenticates with the cached challenge
+	ds.mu.Lock()
+	ds.failNext = true
+	ds.mu.Unlock()
+	if _, err := client.Get(context.Background(), "/status", nil, nil); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if ds.challenges != 1 || strings.Join(ds.counts, ",") != "00000001,00000002,00000003" {
+		t.Errorf("Expected retries to count up the cached nonce, got %d challenges and counts %v", ds.challenges, ds.counts)
+	}
+}
+
+func TestDigestAuthWithXSRFRefresh(t *testing.T) {
+	ds := newDigestServer(t, "auth", "SHA-256")
+	var mu sync.Mutex
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if r.URL.Path == "/csrf" {
+			tokens++
+			http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: fmt.Sprintf("token-%d", tokens), Path: "/"})
+			mu.Unlock()
+			return
+		}
+		mu.Unlock()
+
+		// Only the second token is accepted from authenticated requests
+		if r.Header.Get("Authorization") != "" && r.Header.Get("X-XSRF-TOKEN") != "token-2" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		ds.handle(w, r)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(infrastructure.NewCookieJar()).
+		SetDigestAuth("admin", "secret").
+		SetXSRFOptions(&models.XSRFOptions{BootstrapPath: "/csrf"})
+
+	// The Digest replay is rejected for its token, and the XSRF replay with a
+	// new token answers the challenge again
+	if _, err := client.Post(context.Background(), "/items", nil, TestUser{Name: "Jane"}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The bootstrap request in between authenticates with nonce count 2
+	if strings.Join(ds.counts, ",") != "00000003" || len(ds.bodies) != 1 || !strings.Contains(ds.bodies[0], "Jane") {
+		t.Errorf("Expected the XSRF replay to answer the challenge with the next nonce count, got counts %v", ds.counts)
+	}
+}
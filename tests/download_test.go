@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// rangeServer serves content with ETag and Range support and aborts the
+// first abortCount responses halfway through the body.
+type rangeServer struct {
+	mu         sync.Mutex
+	content    []byte
+	etag       string
+	abortCount int
+	ranges     []string
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	abort := s.abortCount > 0
+	if abort {
+		s.abortCount--
+	}
+	content, etag := s.content, s.etag
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", etag)
+	if abort {
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
+
+func TestDownloadResumesAfterInterruption(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	handler := &rangeServer{content: content, etag: `"v1"`, abortCount: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var progress [][2]int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		}).
+		SetDownloadProgress(func(loaded, total int64) {
+			progress = append(progress, [2]int64{loaded, total})
+		})
+
+	dst := filepath.Join(t.TempDir(), "artifact.bin")
+	result, err := client.Download(context.Background(), "/artifact.bin", dst)
+	if err != nil {
+		t.Fatalf("Expected download to succeed, got %v", err)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, content) {
+		t.Fatalf("Expected downloaded content to match, got %d bytes", len(data))
+	}
+
+	if result.Size != int64(len(content)) || result.Resumed != 1 || result.StatusCode != http.StatusPartialContent {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if handler.ranges[1] != "bytes="+strconv.Itoa(len(content)/2)+"-" {
+		t.Errorf("Expected resume from the midpoint, got ranges %v", handler.ranges)
+	}
+
+	last := progress[len(progress)-1]
+	if last[0] != int64(len(content)) || last[1] != int64(len(content)) {
+		t.Errorf("Expected final progress to report absolute offsets, got %v", last)
+	}
+
+	if _, err := os.Stat(dst + ".part"); !os.IsNotExist(err) {
+		t.Error("Expected partial file to be removed")
+	}
+	if _, err := os.Stat(dst + ".part.json"); !os.IsNotExist(err) {
+		t.Error("Expected download state to be removed")
+	}
+}
+
+func TestDownloadResumesAcrossCalls(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 10000)
+	handler := &rangeServer{content: content, etag: `"v1"`, abortCount: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	dst := filepath.Join(t.TempDir(), "file.bin")
+
+	if _, err := client.Download(context.Background(), "/file.bin", dst); err == nil {
+		t.Fatal("Expected first download to be interrupted")
+	}
+
+	info, err := os.Stat(dst + ".part")
+	if err != nil || info.Size() != int64(len(content)/2) {
+		t.Fatalf("Expected half of the file to be kept, got %v %v", info, err)
+	}
+
+	result, err := client.Download(context.Background(), "/file.bin", dst)
+	if err != nil {
+		t.Fatalf("Expected resumed download to succeed, got %v", err)
+	}
+
+	if result.Resumed != 1 {
+		t.Errorf("Expected download to resume, got %+v", result)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, content) {
+		t.Error("Expected resumed content to match")
+	}
+}
+
+func TestDownloadRestartsWhenResourceChanged(t *testing.T) {
+	handler := &rangeServer{content: bytes.Repeat([]byte("a"), 8000), etag: `"v1"`, abortCount: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	dst := filepath.Join(t.TempDir(), "file.bin")
+
+	if _, err := client.Download(context.Background(), "/file.bin", dst); err == nil {
+		t.Fatal("Expected first download to be interrupted")
+	}
+
+	// The resource changes, so If-Range fails and the full body is sent
+	handler.mu.Lock()
+	handler.content = bytes.Repeat([]byte("b"), 6000)
+	handler.etag = `"v2"`
+	handler.mu.Unlock()
+
+	result, err := client.Download(context.Background(), "/file.bin", dst)
+	if err != nil {
+		t.Fatalf("Expected download to succeed, got %v", err)
+	}
+
+	if result.Resumed != 0 || result.StatusCode != http.StatusOK {
+		t.Errorf("Expected a full restart, got %+v", result)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, bytes.Repeat([]byte("b"), 6000)) {
+		t.Error("Expected new content without stale bytes")
+	}
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func sha256Field(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func TestIntegrityVerifiesContentDigest(t *testing.T) {
+	body := []byte(`{"id":1,"name":"John Doe","email":"john@example.com"}`)
+	sum := sha512.Sum512(body)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Digest", sha256Field(body)+", sha-512=:"+base64.StdEncoding.EncodeToString(sum[:])+":, md5=:AAAA:")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true})
+
+	var user TestUser
+	if _, err := client.Get(context.Background(), "/users/1", nil, &user); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Name != "John Doe" {
+		t.Errorf("Expected decoded user, got %+v", user)
+	}
+}
+
+func TestIntegrityMismatchIsRetried(t *testing.T) {
+	body := []byte("payload")
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sum := md5.Sum(body)
+		w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Write([]byte("paXload"))
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true}).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	resp, err := client.Get(context.Background(), "/data", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if string(resp.RawBody) != "payload" || atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Expected second attempt to succeed, got %q after %d attempts", resp.RawBody, attempts)
+	}
+}
+
+func TestIntegrityChecksumMismatchReturnsTypedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	expected := sha256.Sum256([]byte("original"))
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	_, err := client.R().
+		Path("/file").
+		Integrity(&models.IntegrityOptions{SHA256: hex.EncodeToString(expected[:])}).
+		Do(context.Background())
+
+	integrityErr, ok := err.(*errors.IntegrityError)
+	if !ok {
+		t.Fatalf("Expected IntegrityError, got %T: %v", err, err)
+	}
+	if integrityErr.Source != "checksum" || integrityErr.Expected != hex.EncodeToString(expected[:]) {
+		t.Errorf("Unexpected error details: %+v", integrityErr)
+	}
+
+	if !infrastructure.NewRetryManager(&models.RetryOptions{MaxRetries: 1}).ShouldRetry(0, http.StatusOK, integrityErr) {
+		t.Error("Expected IntegrityError to be retryable")
+	}
+}
+
+func TestIntegrityStreamVerifiesAtEOF(t *testing.T) {
+	body := bytes.Repeat([]byte("stream"), 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Repr-Digest", sha256Field([]byte("something else")))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true})
+
+	resp, err := client.GetStream(context.Background(), "/stream", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	var integrityErr *errors.IntegrityError
+	if !stderrors.As(err, &integrityErr) || integrityErr.Source != "Repr-Digest" {
+		t.Fatalf("Expected Repr-Digest mismatch at EOF, got %v", err)
+	}
+}
+
+func TestIntegrityVerifiesCompressedResponses(t *testing.T) {
+	body := []byte(`{"id":1,"name":"John Doe","email":"john@example.com"}`)
+	var encoded bytes.Buffer
+	gz := gzip.NewWriter(&encoded)
+	gz.Write(body)
+	gz.Close()
+
+	digest := sha256Field(encoded.Bytes())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Digest", digest)
+		w.Write(encoded.Bytes())
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true})
+
+	// The digest covers the gzip bytes as received
+	var user TestUser
+	if _, err := client.Get(context.Background(), "/users/1", nil, &user); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Name != "John Doe" {
+		t.Errorf("Expected decoded user, got %+v", user)
+	}
+
+	digest = sha256Field(body)
+	_, err := client.Get(context.Background(), "/users/1", nil, &user)
+	if integrityErr, ok := err.(*errors.IntegrityError); !ok || integrityErr.Source != "Content-Digest" {
+		t.Fatalf("Expected Content-Digest mismatch, got %v", err)
+	}
+
+	resp, err := client.GetStream(context.Background(), "/users/1", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	var integrityErr *errors.IntegrityError
+	if !stderrors.As(err, &integrityErr) {
+		t.Fatalf("Expected Content-Digest mismatch at EOF, got %v", err)
+	}
+}
+
+func TestIntegrityResumedDownloadVerifiesWholeFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 5000)
+	handler := &rangeServer{content: content, etag: `"v1"`, abortCount: 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Repr-Digest", sha256Field(content))
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true}).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   1,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	result, err := client.Download(context.Background(), "/file.bin", dst)
+	if err != nil {
+		t.Fatalf("Expected verified download, got %v", err)
+	}
+	if result.Resumed != 1 {
+		t.Errorf("Expected download to resume, got %+v", result)
+	}
+}
+
+func TestIntegrityDownloadDiscardsCorruptFile(t *testing.T) {
+	content := []byte("corrupted content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Digest", sha256Field([]byte("expected content")))
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetIntegrityOptions(&models.IntegrityOptions{VerifyDigests: true})
+
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	_, err := client.Download(context.Background(), "/file.bin", dst)
+	if _, ok := err.(*errors.IntegrityError); !ok {
+		t.Fatalf("Expected IntegrityError, got %T: %v", err, err)
+	}
+
+	for _, path := range []string{dst, dst + ".part", dst + ".part.json"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", filepath.Base(path))
+		}
+	}
+}
+
+func TestIntegritySegmentedDownloadChecksAssembledFile(t *testing.T) {
+	content := bytes.Repeat([]byte("segment"), 4000)
+	handler := &rangeServer{content: content, etag: `"v1"`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	sum := sha256.Sum256(content)
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	dst := filepath.Join(t.TempDir(), "file.bin")
+
+	_, err := client.R().
+		Path("/file.bin").
+		Integrity(&models.IntegrityOptions{SHA256: hex.EncodeToString(sum[:])}).
+		DownloadSegmented(context.Background(), dst, 3)
+	if err != nil {
+		t.Fatalf("Expected verified download, got %v", err)
+	}
+
+	wrong := sha256.Sum256([]byte("other"))
+	_, err = client.R().
+		Path("/file.bin").
+		Integrity(&models.IntegrityOptions{SHA256: hex.EncodeToString(wrong[:])}).
+		DownloadSegmented(context.Background(), filepath.Join(t.TempDir(), "bad.bin"), 3)
+	if _, ok := err.(*errors.IntegrityError); !ok {
+		t.Fatalf("Expected IntegrityError, got %T: %v", err, err)
+	}
+}
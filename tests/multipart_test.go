@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Expected multipart body, got error: %v", err)
+		}
+
+		if r.FormValue("title") != "Holiday" {
+			t.Errorf("Expected title field, got %q", r.FormValue("title"))
+		}
+
+		file, header, err := r.FormFile("notes")
+		if err != nil {
+			t.Fatalf("Expected notes file, got error: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if string(content) != "some notes" || header.Filename != "notes.txt" {
+			t.Errorf("Expected notes.txt content, got %q (%s)", content, header.Filename)
+		}
+		if header.Header.Get("Content-Type") != "text/markdown" {
+			t.Errorf("Expected custom part content type, got %q", header.Header.Get("Content-Type"))
+		}
+
+		photo, header, err := r.FormFile("photo")
+		if err != nil {
+			t.Fatalf("Expected photo file, got error: %v", err)
+		}
+		content, _ = io.ReadAll(photo)
+		if string(content) != "jpeg bytes" || header.Filename != "beach.jpg" {
+			t.Errorf("Expected photo from path, got %q (%s)", content, header.Filename)
+		}
+		if header.Header.Get("Content-Type") != "image/jpeg" {
+			t.Errorf("Expected content type from extension, got %q", header.Header.Get("Content-Type"))
+		}
+
+		if got := r.MultipartForm.Value["meta"]; len(got) != 1 || got[0] != `{"a":1}` {
+			t.Errorf("Expected custom part, got %v", got)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "beach.jpg")
+	if err := os.WriteFile(path, []byte("jpeg bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	metaHeader := textproto.MIMEHeader{}
+	metaHeader.Set("Content-Disposition", `form-data; name="meta"`)
+	metaHeader.Set("Content-Type", "application/json")
+
+	form := infrastructure.NewMultipart().
+		Field("title", "Holiday").
+		FileWithContentType("notes", "notes.txt", "text/markdown", strings.NewReader("some notes")).
+		FileFromPath("photo", path).
+		Part(metaHeader, strings.NewReader(`{"a":1}`))
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	resp, err := client.Post(context.Background(), "/photos", nil, form, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.StatusCode)
+	}
+}
+
+func TestMultipartProgressTotal(t *testing.T) {
+	var receivedLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedLength = r.ContentLength
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	var lastLoaded, lastTotal int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetUploadProgress(func(loaded, total int64) {
+			lastLoaded, lastTotal = loaded, total
+		})
+
+	form := infrastructure.NewMultipart().
+		Field("name", "archive").
+		File("data", "data.bin", bytes.NewReader(bytes.Repeat([]byte("x"), 64*1024)))
+
+	if _, err := client.Post(context.Background(), "/upload", nil, form, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if form.Size() <= 64*1024 {
+		t.Fatalf("Expected size to include framing, got %d", form.Size())
+	}
+
+	if receivedLength != form.Size() {
+		t.Errorf("Expected Content-Length %d, got %d", form.Size(), receivedLength)
+	}
+
+	if lastTotal != form.Size() || lastLoaded != form.Size() {
+		t.Errorf("Expected progress to reach %d/%d, got %d/%d", form.Size(), form.Size(), lastLoaded, lastTotal)
+	}
+}
+
+func TestMultipartUnknownSizeAndReplay(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Attempt %d: expected multipart body, got error: %v", attempts, err)
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.FormValue("field") != "value" {
+			t.Errorf("Expected field on replay, got %q", r.FormValue("field"))
+		}
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   1,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	// Seekable readers and fields are replayed on retry
+	form := infrastructure.NewMultipart().
+		Field("field", "value").
+		File("file", "a.txt", strings.NewReader("abc"))
+	if _, err := client.Post(context.Background(), "/", nil, form, nil); err != nil {
+		t.Fatalf("Expected replayed multipart to succeed, got %v", err)
+	}
+
+	// A plain io.Reader has no known size and can only be sent once
+	attempts = 0
+	oneShot := infrastructure.NewMultipart().File("file", "a.txt", io.MultiReader(strings.NewReader("abc")))
+	if oneShot.Size() != -1 {
+		t.Errorf("Expected unknown size, got %d", oneShot.Size())
+	}
+	if _, err := client.Post(context.Background(), "/", nil, oneShot, nil); err == nil {
+		t.Error("Expected error when a one-shot body has to be replayed")
+	}
+}
+
+func TestMultipartInterceptorErrorClosesBody(t *testing.T) {
+	client := infrastructure.NewClient().
+		SetBaseURL("http://127.0.0.1:1").
+		SetCompressionOptions(&models.CompressionOptions{Encoding: infrastructure.EncodingGzip}).
+		AddRequestInterceptor(func(req *http.Request) (*http.Request, error) {
+			return nil, io.ErrUnexpectedEOF
+		})
+
+	before := runtime.NumGoroutine()
+
+	form := infrastructure.NewMultipart().
+		Field("title", "Holiday").
+		File("data", "data.bin", io.LimitReader(zeroReader{}, 1<<20))
+	_, err := client.Post(context.Background(), "/upload", nil, form, nil)
+	if err == nil {
+		t.Fatal("Expected interceptor error")
+	}
+
+	// The multipart and compression writers stop once the body is closed
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected body writers to exit, %d goroutines left over", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// zeroReader is an endless source of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestNDJSONStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/x-ndjson, application/jsonl, application/json;q=0.9" {
+			t.Errorf("Expected NDJSON Accept header, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 1; i <= 100; i++ {
+			fmt.Fprintf(w, "{\"id\":%d,\"name\":\"user-%d\"}\r\n", i, i)
+			if i == 50 {
+				fmt.Fprint(w, "\n") // blank lines are skipped
+			}
+		}
+		fmt.Fprint(w, `{"id":101,"name":"last"}`) // final line without newline
+	}))
+	defer server.Close()
+
+	transformed := 0
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetDataTransformer(func(data []byte) ([]byte, error) {
+			transformed++
+			return bytes.Replace(data, []byte("user-"), []byte("u"), 1), nil
+		})
+
+	var users []TestUser
+	for user, err := range gofetch.GetNDJSON[TestUser](context.Background(), client, "/users", nil) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		users = append(users, user)
+	}
+
+	if len(users) != 101 {
+		t.Fatalf("Expected 101 users, got %d", len(users))
+	}
+
+	if users[0].Name != "u1" || users[100].Name != "last" {
+		t.Errorf("Expected transformed names, got %q and %q", users[0].Name, users[100].Name)
+	}
+
+	if transformed != 101 {
+		t.Errorf("Expected transformer to run per line, ran %d times", transformed)
+	}
+}
+
+func TestNDJSONEarlyStopAndErrors(t *testing.T) {
+	disconnected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			fmt.Fprint(w, "{\"id\":1}\nnot json\n{\"id\":3}\n")
+			return
+		}
+
+		// An endless stream only ends when the consumer goes away
+		for i := 0; ; i++ {
+			if _, err := fmt.Fprintf(w, "{\"id\":%d}\n", i); err != nil {
+				break
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				close(disconnected)
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+		close(disconnected)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	count := 0
+	for _, err := range infrastructure.StreamNDJSON[TestUser](context.Background(), client.R().Path("/endless")) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected connection to close when iteration stops")
+	}
+
+	var lastErr error
+	ids := 0
+	for _, err := range infrastructure.StreamNDJSON[TestUser](context.Background(), client.R().Path("/broken")) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ids++
+	}
+
+	if ids != 1 || lastErr == nil {
+		t.Errorf("Expected 1 item then an error, got %d items and %v", ids, lastErr)
+	}
+}
+
+func TestNDJSONContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{\"id\":1}\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lastErr error
+	for _, err := range gofetch.GetNDJSON[TestUser](ctx, client, "/", nil) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		cancel()
+	}
+
+	if lastErr == nil {
+		t.Error("Expected cancellation error")
+	}
+}
@@ -0,0 +1,355 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// tokenServer is a local OAuth2 token endpoint and protected API.
+type tokenServer struct {
+	*httptest.Server
+	requests  atomic.Int32
+	expiresIn int
+	delay     time.Duration
+
+	mu      sync.Mutex
+	grants  []string
+	current string
+	revoked bool
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	t.Helper()
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(ts.handle))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *tokenServer) handle(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/token":
+		time.Sleep(ts.delay)
+		r.ParseForm()
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+
+		grant := r.PostForm.Get("grant_type")
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.grants = append(ts.grants, grant)
+		switch {
+		case grant == "password" && r.PostForm.Get("password") != "hunter2",
+			grant == "refresh_token" && (ts.revoked || r.PostForm.Get("refresh_token") == ""):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		n := ts.requests.Add(1)
+		ts.current = fmt.Sprintf("access-%d", n)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  ts.current,
+			"token_type":    "bearer",
+			"expires_in":    ts.expiresIn,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+			"scope":         r.PostForm.Get("scope"),
+		})
+	default:
+		ts.mu.Lock()
+		valid := r.Header.Get("Authorization") == "Bearer "+ts.current && r.URL.Path != "/revoked"
+		ts.mu.Unlock()
+		if !valid {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="expired"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":1,"name":"Jane"}`))
+	}
+}
+
+func (ts *tokenServer) rotate() {
+	ts.mu.Lock()
+	ts.current = "rotated"
+	ts.mu.Unlock()
+}
+
+func (ts *tokenServer) grantLog() []string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]string(nil), ts.grants...)
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"read", "write"},
+		})
+
+	for i := 0; i < 3; i++ {
+		var user TestUser
+		if _, err := client.Get(context.Background(), "/me", nil, &user); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if ts.requests.Load() != 1 {
+		t.Errorf("Expected the token to be cached, got %d token requests", ts.requests.Load())
+	}
+
+	token, err := client.OAuth2Token(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if token.AccessToken != "access-1" || token.Scope != "read write" || token.Expiry.IsZero() {
+		t.Errorf("Unexpected token %+v", token)
+	}
+}
+
+func TestOAuth2SingleFlight(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	ts.delay = 50 * time.Millisecond
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+			AuthStyle:    models.AuthStyleParams,
+		})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Get(context.Background(), "/me", nil, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	if ts.requests.Load() != 1 {
+		t.Errorf("Expected concurrent requests to share one token request, got %d", ts.requests.Load())
+	}
+}
+
+func TestOAuth2RefreshesExpiringToken(t *testing.T) {
+	// Tokens expiring within the skew are renewed with the refresh token
+	ts := newTokenServer(t, 5)
+	var rotated []string
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+			Grant:        models.GrantPassword,
+			Username:     "jane",
+			Password:     "hunter2",
+			OnToken: func(token *models.OAuth2Token) {
+				rotated = append(rotated, token.RefreshToken)
+			},
+		})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), "/me", nil, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if got := ts.grantLog(); len(got) != 2 || got[0] != "password" || got[1] != "refresh_token" {
+		t.Errorf("Expected password then refresh_token grants, got %v", got)
+	}
+	if len(rotated) != 2 || rotated[1] != "refresh-2" {
+		t.Errorf("Expected OnToken with each new refresh token, got %v", rotated)
+	}
+
+	// A revoked refresh token falls back to the configured grant
+	ts.mu.Lock()
+	ts.revoked = true
+	ts.mu.Unlock()
+	if _, err := client.Get(context.Background(), "/me", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := ts.grantLog(); len(got) != 4 || got[3] != "password" {
+		t.Errorf("Expected fallback to the password grant, got %v", got)
+	}
+}
+
+func TestOAuth2RefreshTokenGrant(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+			RefreshToken: "initial",
+		})
+
+	if _, err := client.Get(context.Background(), "/me", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := ts.grantLog(); len(got) != 1 || got[0] != "refresh_token" {
+		t.Errorf("Expected a refresh_token grant, got %v", got)
+	}
+}
+
+func TestOAuth2ReplaysInvalidToken(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+		})
+
+	if _, err := client.Get(context.Background(), "/me", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The server revokes the cached token before it expires
+	ts.rotate()
+	var user TestUser
+	if _, err := client.Post(context.Background(), "/me", nil, TestUser{Name: "Jane"}, &user); err != nil {
+		t.Fatalf("Expected request to be replayed with a new token, got %v", err)
+	}
+	if user.Name != "Jane" || ts.requests.Load() != 2 {
+		t.Errorf("Expected one token renewal, got %d token requests (%+v)", ts.requests.Load(), user)
+	}
+
+	// A token that is rejected again is not replayed twice
+	_, err := client.Get(context.Background(), "/revoked", nil, nil)
+	if httpErr, ok := err.(*errors.HTTPError); !ok || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after one replay, got %v", err)
+	}
+	if ts.requests.Load() != 3 {
+		t.Errorf("Expected one more token renewal, got %d token requests", ts.requests.Load())
+	}
+}
+
+func TestOAuth2TokenError(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetRetryOptions(&models.RetryOptions{MaxRetries: 3, InitialDelay: time.Millisecond}).
+		SetOAuth2(&models.OAuth2Options{
+			TokenURL:     ts.URL + "/token",
+			ClientID:     "client",
+			ClientSecret: "wrong",
+		})
+
+	_, err := client.Get(context.Background(), "/me", nil, nil)
+	oauthErr, ok := err.(*errors.OAuth2Error)
+	if !ok {
+		t.Fatalf("Expected OAuth2Error, got %T: %v", err, err)
+	}
+	if oauthErr.Code != "invalid_client" || oauthErr.StatusCode != http.StatusUnauthorized || oauthErr.Description != "bad credentials" {
+		t.Errorf("Unexpected error %+v", oauthErr)
+	}
+	if got := ts.grantLog(); len(got) != 0 {
+		t.Errorf("Expected rejected token requests not to be retried, got %v", got)
+	}
+}
+
+func TestOAuth2KeepsExplicitAuthorization(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetHeader("Authorization", "Bearer manual").
+		SetOAuth2(&models.OAuth2Options{TokenURL: ts.URL + "/token", ClientID: "client", ClientSecret: "s3cret"})
+
+	if _, err := client.Get(context.Background(), "/me", nil, nil); err == nil {
+		t.Error("Expected the manual token to be sent and rejected")
+	}
+	if ts.requests.Load() != 0 {
+		t.Errorf("Expected no token request, got %d", ts.requests.Load())
+	}
+}
+
+func TestOAuth2TokenNotSentToOtherOrigins(t *testing.T) {
+	var received string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	ts := newTokenServer(t, 3600)
+	client := infrastructure.NewClient().
+		SetBaseURL(ts.URL).
+		SetOAuth2(&models.OAuth2Options{TokenURL: ts.URL + "/token", ClientID: "client", ClientSecret: "s3cret"})
+
+	if _, err := client.Get(context.Background(), other.URL+"/collect", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "" {
+		t.Errorf("Expected no token for another origin, got %q", received)
+	}
+	if ts.requests.Load() != 0 {
+		t.Errorf("Expected no token request, got %d", ts.requests.Load())
+	}
+
+	// Absolute URLs on the base URL's origin are authenticated
+	if _, err := client.Get(context.Background(), ts.URL+"/me", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestOAuth2OriginsWithoutBaseURL(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	var received string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer api.Close()
+
+	// Without a base URL or origins, the token would never be sent
+	options := &models.OAuth2Options{TokenURL: ts.URL + "/token", ClientID: "client", ClientSecret: "s3cret"}
+	client := infrastructure.NewClient().SetOAuth2(options)
+	if _, err := client.Get(context.Background(), api.URL+"/me", nil, nil); err == nil || !strings.Contains(err.Error(), "Origins") {
+		t.Fatalf("Expected a configuration error, got %v", err)
+	}
+
+	options.Origins = []string{api.URL}
+	client.SetOAuth2(options)
+	if _, err := client.Get(context.Background(), api.URL+"/me", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(received, "Bearer ") {
+		t.Errorf("Expected a bearer token for a listed origin, got %q", received)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestPathPlaceholdersAreMatchedExactly(t *testing.T) {
+	client := infrastructure.NewClient().SetBaseURL("https://api.example.com")
+
+	fullURL, err := client.R().
+		Path("/lists/:id/items/:idx").
+		Param("id", 7).
+		Param("idx", 3).
+		URL()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fullURL != "https://api.example.com/lists/7/items/3" {
+		t.Errorf("Expected :id not to corrupt :idx, got %s", fullURL)
+	}
+}
+
+func TestPathPlaceholderSyntaxes(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/files/{id}.json", "/files/42.json"},
+		{"/files/:id.json", "/files/42.json"},
+		{"/v1/items:batchGet/{id}", "/v1/items:batchGet/42"},
+		{"/files/:id?fields=a:b", "/files/42?fields=a:b"},
+	}
+
+	for _, tt := range tests {
+		fullURL, err := client.R().Path(tt.path).PathParam("id", 42).URL()
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.path, err)
+			continue
+		}
+		if fullURL != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.expected, fullURL)
+		}
+	}
+}
+
+func TestPathParametersAreEscaped(t *testing.T) {
+	var rawPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawPath = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	_, err := client.Get(context.Background(), "/files/:name", map[string]interface{}{
+		"name": "reports/2024 q1?.pdf",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if rawPath != "/files/reports%2F2024%20q1%3F.pdf" {
+		t.Errorf("Expected the value to stay in one segment, got %s", rawPath)
+	}
+}
+
+func TestMissingPathParameter(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	_, err := client.R().Path("/users/:id/posts/{post}").Param("id", 1).URL()
+	if err == nil || !strings.Contains(err.Error(), `missing path parameter "post"`) {
+		t.Errorf("Expected a missing parameter error, got %v", err)
+	}
+
+	_, err = client.Get(context.Background(), "/users/:id", map[string]interface{}{"id": nil}, nil)
+	if err == nil || !strings.Contains(err.Error(), `missing path parameter "id"`) {
+		t.Errorf("Expected a missing parameter error for a nil value, got %v", err)
+	}
+}
+
+func TestUnknownPathParameter(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	_, err := client.R().Path("/users/:id").PathParam("id", 1).PathParam("org", "acme").URL()
+	if err == nil || !strings.Contains(err.Error(), `unknown path parameter "org"`) {
+		t.Errorf("Expected an unknown parameter error, got %v", err)
+	}
+}
+
+func TestPathParamsAreSeparateFromQuery(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	fullURL, err := client.R().
+		Path("/users/{id}").
+		PathParam("id", 5).
+		Query("id", "other").
+		Param("page", 2).
+		URL()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fullURL != "/users/5?id=other&page=2" {
+		t.Errorf("Expected path and query parameters to stay separate, got %s", fullURL)
+	}
+}
+
+func TestInvalidPathTemplate(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	for _, path := range []string{"/users/{id", "/users/{}", "/users/{a b}"} {
+		if _, err := client.R().Path(path).URL(); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+
+	_, err := client.R().Path("/users/:id").Param("id", []int{1, 2}).URL()
+	if err == nil {
+		t.Error("Expected an error for a list path parameter")
+	}
+}
@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+type searchFilter struct {
+	Status string `url:"status"`
+	Owner  string `url:"owner,omitempty"`
+}
+
+type pagination struct {
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+type searchOptions struct {
+	pagination
+	Query   string            `url:"q"`
+	Tags    []string          `url:"tag"`
+	IDs     []int             `url:"ids,comma"`
+	Labels  []string          `url:"label,brackets"`
+	Since   time.Time         `url:"since" layout:"2006-01-02"`
+	Until   time.Time         `url:"until,unix"`
+	Draft   bool              `url:"draft,int"`
+	Filter  searchFilter      `url:"filter"`
+	Extra   map[string]string `url:"extra,omitempty"`
+	Cursor  *string           `url:"cursor,omitempty"`
+	Ignored string            `url:"-"`
+	secret  string
+}
+
+// captureQuery starts a server that records the raw query of each request.
+func captureQuery(t *testing.T) (*httptest.Server, *string) {
+	t.Helper()
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, &rawQuery
+}
+
+func TestEncodeQueryStruct(t *testing.T) {
+	options := searchOptions{
+		pagination: pagination{Page: 2},
+		Query:      "go http",
+		Tags:       []string{"a", "b"},
+		IDs:        []int{1, 2, 3},
+		Labels:     []string{"x", "y"},
+		Since:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Until:      time.Unix(1700000000, 0),
+		Draft:      true,
+		Filter:     searchFilter{Status: "open"},
+		Ignored:    "ignored",
+		secret:     "secret",
+	}
+
+	values, err := infrastructure.EncodeQuery(options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := url.Values{
+		"page":           {"2"},
+		"q":              {"go http"},
+		"tag":            {"a", "b"},
+		"ids":            {"1,2,3"},
+		"label[]":        {"x", "y"},
+		"since":          {"2024-03-01"},
+		"until":          {"1700000000"},
+		"draft":          {"1"},
+		"filter[status]": {"open"},
+	}
+	if values.Encode() != expected.Encode() {
+		t.Errorf("Expected %s, got %s", expected.Encode(), values.Encode())
+	}
+}
+
+func TestEncodeQueryNestedMaps(t *testing.T) {
+	values, err := infrastructure.EncodeQuery(map[string]interface{}{
+		"filter": map[string]interface{}{
+			"status": "open",
+			"range":  map[string]int{"min": 1, "max": 9},
+		},
+		"sort": []map[string]string{{"field": "name"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "filter%5Brange%5D%5Bmax%5D=9&filter%5Brange%5D%5Bmin%5D=1&filter%5Bstatus%5D=open&sort%5B0%5D%5Bfield%5D=name"
+	if values.Encode() != expected {
+		t.Errorf("Expected %s, got %s", expected, values.Encode())
+	}
+}
+
+func TestEncodeQueryRejectsScalars(t *testing.T) {
+	if _, err := infrastructure.EncodeQuery(42, nil); err == nil {
+		t.Error("Expected an error for a value without a key")
+	}
+	if _, err := infrastructure.EncodeQuery(map[string]interface{}{"ch": make(chan int)}, nil); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+}
+
+func TestParamsEncodeSlicesAndTimes(t *testing.T) {
+	server, rawQuery := captureQuery(t)
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	_, err := client.Get(context.Background(), "/search", map[string]interface{}{
+		"tag":   []string{"a", "b"},
+		"since": time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		"ratio": 1000000.5,
+		"none":  nil,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "ratio=1000000.5&since=2024-03-01T12%3A00%3A00Z&tag=a&tag=b"
+	if *rawQuery != expected {
+		t.Errorf("Expected %s, got %s", expected, *rawQuery)
+	}
+}
+
+func TestQueryOptionsArrayFormatAndTimeLayout(t *testing.T) {
+	server, rawQuery := captureQuery(t)
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetQueryOptions(&models.QueryOptions{
+			ArrayFormat: models.ArrayBrackets,
+			TimeLayout:  time.DateOnly,
+		})
+
+	_, err := client.R().
+		Path("/search").
+		Query("id", []int{1, 2}).
+		Query("day", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "day=2024-03-01&id%5B%5D=1&id%5B%5D=2"
+	if *rawQuery != expected {
+		t.Errorf("Expected %s, got %s", expected, *rawQuery)
+	}
+}
+
+func TestQueryStructOnRequest(t *testing.T) {
+	server, rawQuery := captureQuery(t)
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	request := client.R().
+		Path("/search").
+		QueryStruct(pagination{Page: 3, PerPage: 50}).
+		Query("q", "go")
+
+	for i := 0; i < 2; i++ {
+		if _, err := request.Clone().Do(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if *rawQuery != "page=3&per_page=50&q=go" {
+			t.Errorf("Expected deterministic query, got %s", *rawQuery)
+		}
+	}
+
+	if _, err := client.R().QueryStruct("invalid").Do(context.Background()); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
+
+func TestStructParamsFillPathAndQuery(t *testing.T) {
+	var path, rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, rawQuery = r.URL.Path, r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	params, err := infrastructure.StructParams(struct {
+		Owner string   `url:"owner"`
+		IDs   []int    `url:"ids,comma"`
+		State string   `url:"state,omitempty"`
+		Sort  []string `url:"sort,omitempty"`
+	}{Owner: "octo", IDs: []int{4, 5}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	if _, err := client.Get(context.Background(), "/repos/:owner", params, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/repos/octo" {
+		t.Errorf("Expected path /repos/octo, got %s", path)
+	}
+	if rawQuery != "ids=4%2C5" {
+		t.Errorf("Expected ids=4%%2C5, got %s", rawQuery)
+	}
+
+	if _, err := infrastructure.StructParams([]int{1}); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
+
+// priority is an enum formatted by its String method.
+type priority int
+
+func (p priority) String() string {
+	return [...]string{"low", "high"}[p]
+}
+
+// version is a struct that is only formatted by its String method.
+type version struct {
+	major, minor int
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("v%d.%d", v.major, v.minor)
+}
+
+func TestParamsFallBackToStringerAndFormatting(t *testing.T) {
+	var path, rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, rawQuery = r.URL.Path, r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	_, err := client.Get(context.Background(), "/releases/:version", map[string]interface{}{
+		"version":  version{major: 1, minor: 2},
+		"priority": priority(1),
+		"timeout":  90 * time.Second,
+		"point":    complex(1, 2),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/releases/v1.2" {
+		t.Errorf("Expected Stringer path value, got %s", path)
+	}
+	expected := "point=%281%2B2i%29&priority=high&timeout=1m30s"
+	if rawQuery != expected {
+		t.Errorf("Expected %s, got %s", expected, rawQuery)
+	}
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// flakyEchoServer fails the first request with 503 and echoes the body of later ones.
+func flakyEchoServer(attempts *int32, bodies *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		*bodies = append(*bodies, string(data))
+		if atomic.AddInt32(attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(data)
+	}))
+}
+
+func retryingClient(baseURL string) *infrastructure.Client {
+	return infrastructure.NewClient().
+		SetBaseURL(baseURL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+}
+
+func TestReaderBodyIsReplayedOnRetry(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := flakyEchoServer(&attempts, &bodies)
+	defer server.Close()
+
+	client := retryingClient(server.URL)
+	resp, err := client.Post(context.Background(), "/upload", nil, strings.NewReader("raw payload"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != "raw payload" || bodies[1] != "raw payload" {
+		t.Errorf("Expected the body to be sent twice, got %q", bodies)
+	}
+	if resp.Headers.Get("Content-Type") != "application/octet-stream" {
+		t.Errorf("Expected default Content-Type, got %q", resp.Headers.Get("Content-Type"))
+	}
+}
+
+func TestReaderFactoryBodyIsStreamed(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := flakyEchoServer(&attempts, &bodies)
+	defer server.Close()
+
+	opened := 0
+	factory := func() (io.ReadCloser, error) {
+		opened++
+		return io.NopCloser(strings.NewReader("line 1\nline 2\n")), nil
+	}
+
+	client := retryingClient(server.URL)
+	resp, err := client.R().
+		Method(http.MethodPut).
+		Path("/logs").
+		Header("Content-Type", "text/plain").
+		Body(factory).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if opened != 2 || bodies[1] != "line 1\nline 2\n" {
+		t.Errorf("Expected the factory to be called per attempt, got %d calls and %q", opened, bodies)
+	}
+	if resp.Headers.Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected Content-Type to be kept, got %q", resp.Headers.Get("Content-Type"))
+	}
+}
+
+func TestOneShotReaderDisablesRetries(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := flakyEchoServer(&attempts, &bodies)
+	defer server.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streamed once"))
+		pw.Close()
+	}()
+
+	client := retryingClient(server.URL)
+	_, err := client.Post(context.Background(), "/upload", nil, pr, nil)
+
+	httpErr, ok := err.(*errors.HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the 503 without retrying, got %v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 1 || bodies[0] != "streamed once" {
+		t.Errorf("Expected a single attempt with the full body, got %q", bodies)
+	}
+}
+
+func TestReaderBodyFollowsTemporaryRedirect(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			io.Copy(io.Discard, r.Body)
+			http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	body := bytes.NewBufferString("moved payload")
+
+	if _, err := client.Post(context.Background(), "/old", nil, body, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "moved payload" {
+		t.Errorf("Expected body to be replayed after redirect, got %q", received)
+	}
+}
+
+func TestTypedNilReaderBodyIsNoBody(t *testing.T) {
+	var lengths []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		lengths = append(lengths, int64(len(data)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var buffer *bytes.Buffer
+	var reader *strings.Reader
+	var form *infrastructure.Multipart
+	var factory func() (io.ReadCloser, error)
+	for _, body := range []interface{}{buffer, reader, form, factory} {
+		if _, err := client.Post(context.Background(), "/upload", nil, body, nil); err != nil {
+			t.Fatalf("Expected no error for %T, got %v", body, err)
+		}
+		if _, err := client.R().Method(http.MethodPut).Path("/upload").Body(body).Do(context.Background()); err != nil {
+			t.Fatalf("Expected no error for %T, got %v", body, err)
+		}
+	}
+	for _, length := range lengths {
+		if length != 0 {
+			t.Errorf("Expected requests without a body, got lengths %v", lengths)
+			break
+		}
+	}
+}
+
+// seekOnlyReader is a seekable reader that does not implement io.ReaderAt.
+type seekOnlyReader struct {
+	reader *strings.Reader
+}
+
+func (r *seekOnlyReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+func (r *seekOnlyReader) Seek(offset int64, whence int) (int64, error) {
+	return r.reader.Seek(offset, whence)
+}
+
+func TestSeekableReaderBodyIsHashedBeforeSending(t *testing.T) {
+	var body, payloadHash string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, payloadHash = string(data), r.Header.Get("X-Amz-Content-Sha256")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetSigV4(&models.SigV4Options{AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "us-east-1", Service: "s3"})
+
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		{"reader at", strings.NewReader("raw payload")},
+		{"seek only", &seekOnlyReader{reader: strings.NewReader("raw payload")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Hashing reads the reader while the body to send is already open
+			if _, err := client.Put(context.Background(), "/bucket/key", nil, tt.body, nil); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			sum := sha256.Sum256([]byte("raw payload"))
+			if body != "raw payload" || payloadHash != hex.EncodeToString(sum[:]) {
+				t.Errorf("Expected the whole body to be hashed and sent, got %q with hash %s", body, payloadHash)
+			}
+		})
+	}
+}
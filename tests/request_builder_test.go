@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestRequestBuilderArbitraryMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		if r.Method == http.MethodHead {
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"method": r.Method})
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	for _, method := range []string{http.MethodHead, http.MethodOptions, "PROPFIND", "PURGE"} {
+		var result map[string]string
+		resp, err := client.R().Method(method).Path("/resource").Into(&result).Do(context.Background())
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", method, err)
+		}
+
+		if resp.Headers.Get("X-Method") != method {
+			t.Errorf("%s: expected server to see method, got %q", method, resp.Headers.Get("X-Method"))
+		}
+
+		if method != http.MethodHead && result["method"] != method {
+			t.Errorf("%s: expected decoded body, got %v", method, result)
+		}
+	}
+}
+
+func TestRequestBuilderParamsQueryAndBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/7" {
+			t.Errorf("Expected path /users/7, got %s", r.URL.Path)
+		}
+		if tags := r.URL.Query()["tag"]; len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+			t.Errorf("Expected repeated tag query values, got %v", tags)
+		}
+		if r.Header.Get("X-Trace") != "on" {
+			t.Errorf("Expected per-request header, got %q", r.Header.Get("X-Trace"))
+		}
+
+		var user TestUser
+		json.NewDecoder(r.Body).Decode(&user)
+		user.ID = 7
+		json.NewEncoder(w).Encode(user)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	var result TestUser
+	_, err := client.R().
+		Method(http.MethodPut).
+		Path("/users/:id").
+		Param("id", 7).
+		Query("tag", "a").
+		Query("tag", "b").
+		Header("X-Trace", "on").
+		Body(TestUser{Name: "Builder"}).
+		Into(&result).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != 7 || result.Name != "Builder" {
+		t.Errorf("Expected echoed user, got %+v", result)
+	}
+}
+
+func TestRequestBuilderCloneAndInspect(t *testing.T) {
+	client := infrastructure.NewClient().
+		SetBaseURL("https://api.example.com").
+		SetHeader("X-App", "gofetch")
+
+	base := client.R().
+		Method("PURGE").
+		Path("/cache/:key").
+		Param("key", "home").
+		Header("X-Scope", "edge")
+
+	clone := base.Clone().
+		Param("key", "about").
+		Query("soft", true).
+		Header("X-Scope", "origin")
+
+	baseURL, err := base.URL()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if baseURL != "https://api.example.com/cache/home" {
+		t.Errorf("Expected original URL to be unchanged, got %s", baseURL)
+	}
+
+	cloneURL, err := clone.URL()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cloneURL != "https://api.example.com/cache/about?soft=true" {
+		t.Errorf("Expected clone URL, got %s", cloneURL)
+	}
+
+	if base.GetMethod() != "PURGE" || clone.GetPath() != "/cache/:key" {
+		t.Error("Expected method and path to be inspectable")
+	}
+
+	if base.GetConfig().Headers["X-Scope"] != "edge" {
+		t.Error("Expected original header to be unchanged by clone")
+	}
+
+	if clone.GetConfig().Headers["X-App"] != "gofetch" {
+		t.Error("Expected effective config to include client headers")
+	}
+}
+
+func TestRequestBuilderUsesRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	req := client.R().Method(http.MethodOptions).Path("/test")
+
+	resp, err := req.Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success after retry, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("Expected 200 after 2 attempts, got %d after %d", resp.StatusCode, attempts)
+	}
+
+	// A built request can be executed again later
+	if _, err := req.Do(context.Background()); err != nil {
+		t.Fatalf("Expected second execution to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestPerRequestHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-App-Version") != "1.0.0" {
+			t.Errorf("Expected client header to be kept, got %q", r.Header.Get("X-App-Version"))
+		}
+		json.NewEncoder(w).Encode(map[string]string{"request_id": r.Header.Get("X-Request-ID")})
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetHeader("X-App-Version", "1.0.0")
+
+	var result map[string]string
+	_, err := client.Get(context.Background(), "/test", nil, &result, &models.Config{
+		Headers: map[string]string{"X-Request-ID": "abc"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result["request_id"] != "abc" {
+		t.Errorf("Expected per-request header to be sent, got %q", result["request_id"])
+	}
+
+	if _, ok := client.Config().Headers["X-Request-ID"]; ok {
+		t.Error("Expected per-request header not to leak into client config")
+	}
+}
+
+func TestPerRequestConfigsMergeInOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Layer")))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	resp, err := client.Post(context.Background(), "/test", nil, nil, nil,
+		&models.Config{Headers: map[string]string{"X-Layer": "first"}},
+		nil,
+		&models.Config{Headers: map[string]string{"X-Layer": "second"}},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(resp.RawBody) != "second" {
+		t.Errorf("Expected later config to win, got %q", string(resp.RawBody))
+	}
+}
+
+func TestPerRequestBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL("http://127.0.0.1:1")
+
+	resp, err := client.Delete(context.Background(), "/users/1", nil, nil, &models.Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+}
+
+func TestPerRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	_, err := client.Get(context.Background(), "/slow", nil, nil, &models.Config{Timeout: 50 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected per-request timeout to be enforced")
+	}
+
+	if client.Config().Timeout != 30*time.Second {
+		t.Errorf("Expected client timeout to stay 30s, got %v", client.Config().Timeout)
+	}
+
+	// A per-request timeout can also be longer than the client default
+	client.SetTimeout(50 * time.Millisecond)
+	_, err = client.Get(context.Background(), "/slow", nil, nil, &models.Config{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("Expected longer per-request timeout to succeed, got %v", err)
+	}
+}
+
+func TestPerRequestStatusValidator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	resp, err := client.Get(context.Background(), "/missing", nil, nil, &models.Config{
+		StatusValidator: func(statusCode int) bool {
+			return statusCode < 500
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected per-request validator to accept 404, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestPerRequestRetryOptions(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   3,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	// Disable retries for this call only
+	_, err := client.Put(context.Background(), "/test", nil, map[string]string{"a": "b"}, nil, &models.Config{
+		RetryOptions: &models.RetryOptions{MaxRetries: 0},
+	})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt with retries disabled per request, got %d", attempts)
+	}
+
+	// Enable retries for a call on a client without retry configuration
+	attempts = 0
+	plain := infrastructure.NewClient().SetBaseURL(server.URL)
+	_, err = plain.Patch(context.Background(), "/test", nil, nil, nil, &models.Config{
+		RetryOptions: &models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts with per-request retries, got %d", attempts)
+	}
+}
+
+func TestPerRequestRetryOptionsKeepClientCircuitBreaker(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	perRequest := &models.Config{
+		RetryOptions: &models.RetryOptions{
+			CircuitBreaker:          true,
+			CircuitBreakerThreshold: 1,
+			CircuitBreakerTimeout:   time.Minute,
+		},
+	}
+
+	merged := client.Config().Merge(perRequest)
+	if merged.RetryOptions.CircuitBreaker {
+		t.Error("Expected per-request circuit breaker settings to be ignored")
+	}
+
+	// Without a client breaker, no circuit opens between calls
+	for i := 0; i < 2; i++ {
+		client.Get(context.Background(), "/test", nil, nil, perRequest)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected both calls to reach the server, got %d", attempts)
+	}
+}
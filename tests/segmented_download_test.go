@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestDownloadSegmentedFetchesRangesConcurrently(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 8192)
+	handler := &rangeServer{content: content, etag: `"v1"`}
+
+	var mu sync.Mutex
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var progressMu sync.Mutex
+	var last [2]int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetHeader("Authorization", "Bearer token").
+		SetDownloadProgress(func(loaded, total int64) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			if loaded < last[0] {
+				t.Errorf("Expected progress to grow, got %d after %d", loaded, last[0])
+			}
+			last = [2]int64{loaded, total}
+		})
+
+	dst := filepath.Join(t.TempDir(), "artifact.bin")
+	result, err := client.DownloadSegmented(context.Background(), "/artifact.bin", dst, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, content) {
+		t.Fatalf("Expected downloaded content to match, got %d bytes", len(data))
+	}
+
+	if result.Size != int64(len(content)) || result.StatusCode != http.StatusPartialContent {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// One probe plus one request per segment
+	if len(handler.ranges) != 5 {
+		t.Fatalf("Expected 5 requests, got %v", handler.ranges)
+	}
+	for _, rng := range handler.ranges[1:] {
+		if !strings.HasPrefix(rng, "bytes=") {
+			t.Errorf("Expected a range request, got %q", rng)
+		}
+	}
+
+	for _, value := range auth {
+		if value != "Bearer token" {
+			t.Errorf("Expected client headers on every request, got %q", value)
+		}
+	}
+
+	if last[0] != int64(len(content)) || last[1] != int64(len(content)) {
+		t.Errorf("Expected aggregated progress to reach the full size, got %v", last)
+	}
+}
+
+func TestDownloadSegmentedRetriesFailedSegment(t *testing.T) {
+	content := bytes.Repeat([]byte("z"), 40000)
+
+	var mu sync.Mutex
+	aborted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		abort := !aborted && r.Header.Get("Range") != ""
+		if abort {
+			aborted = true
+		}
+		mu.Unlock()
+
+		if abort {
+			w.Header().Set("Content-Range", "bytes 0-19999/40000")
+			w.Header().Set("Content-Length", "20000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[:100])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   2,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	result, err := client.DownloadSegmented(context.Background(), "/file.bin", dst, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, content) {
+		t.Error("Expected content to match after segment retry")
+	}
+	if !aborted {
+		t.Error("Expected one segment to be interrupted")
+	}
+	if result.Resumed > 1 {
+		t.Errorf("Expected at most one resumed segment, got %+v", result)
+	}
+}
+
+func TestDownloadSegmentedFallsBackWithoutRanges(t *testing.T) {
+	content := []byte("no ranges here")
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	dst := filepath.Join(t.TempDir(), "file.txt")
+
+	result, err := client.DownloadSegmented(context.Background(), "/file.txt", dst, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(dst)
+	if !bytes.Equal(data, content) {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected single stream download, got %+v", result)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Errorf("Expected HEAD probe then GET, got %v", methods)
+	}
+}
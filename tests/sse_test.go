@@ -0,0 +1,251 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestEventSourceParsesAndReconnects(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	var lastEventIDs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connections++
+		connection := connections
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Expected client headers on connection %d", connection)
+		}
+
+		switch connection {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "\uFEFF: keep-alive\r\n\r\n")
+			fmt.Fprint(w, "retry: 10\r\nid: 1\r\ndata: hello\r\n\r\n")
+			fmt.Fprint(w, "event: update\r\ndata: line one\r\ndata:line two\r\nid: 2\r\n\r\n")
+			fmt.Fprint(w, "data: incomplete") // dropped connection mid-event
+		case 2:
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			fmt.Fprint(w, "data\rdata: after reconnect\r\r")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetHeader("Authorization", "Bearer token")
+
+	source := client.NewEventSource("/events", nil).
+		SetRetryOptions(&models.RetryOptions{MaxRetries: 3, InitialDelay: 10 * time.Millisecond})
+
+	var events []*models.ServerSentEvent
+	for event, err := range source.Events(context.Background()) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	if events[0].ID != "1" || events[0].Event != "message" || events[0].Data != "hello" || events[0].Retry != 10*time.Millisecond {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+
+	if events[1].ID != "2" || events[1].Event != "update" || events[1].Data != "line one\nline two" {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+
+	if events[2].ID != "2" || events[2].Data != "\nafter reconnect" {
+		t.Errorf("Unexpected third event: %+v", events[2])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 3 {
+		t.Errorf("Expected 3 connections, got %d", connections)
+	}
+	if lastEventIDs[0] != "" || lastEventIDs[1] != "2" || lastEventIDs[2] != "2" {
+		t.Errorf("Expected Last-Event-ID on reconnect, got %v", lastEventIDs)
+	}
+	if source.LastEventID() != "2" {
+		t.Errorf("Expected last event ID 2, got %q", source.LastEventID())
+	}
+}
+
+func TestEventSourceSubscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "id: %d\ndata: {\"n\":%d}\n\n", i, i)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, errs := client.R().Method(http.MethodPost).Path("/stream").EventSource().Subscribe(ctx)
+
+	for i := 1; i <= 3; i++ {
+		event := <-events
+		if event.ID != fmt.Sprint(i) {
+			t.Errorf("Expected event %d, got %+v", i, event)
+		}
+	}
+	cancel()
+
+	for range events {
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("Expected no error after cancellation, got %v", err)
+	}
+
+	// Non-retryable statuses end the stream with the HTTP error
+	events, errs = client.NewEventSource("/gone", nil).Subscribe(context.Background())
+	for range events {
+	}
+	httpErr, ok := (<-errs).(*errors.HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 HTTPError, got %v", httpErr)
+	}
+}
+
+func TestEventSourceGivesUp(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{MaxRetries: 2, InitialDelay: 5 * time.Millisecond})
+
+	var lastErr error
+	for _, err := range client.NewEventSource("/events", nil).Events(context.Background()) {
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		t.Fatal("Expected error after exhausting reconnection attempts")
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 connection attempts, got %d", attempts)
+	}
+}
+
+func TestEventSourceRetryWithoutData(t *testing.T) {
+	var mu sync.Mutex
+	var connected []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connected = append(connected, time.Now())
+		connection := len(connected)
+		mu.Unlock()
+
+		switch connection {
+		case 1:
+			// A block with only a retry field dispatches no event
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "retry: 20\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	// The backoff would wait far longer than the server's reconnection time
+	source := client.NewEventSource("/events", nil).
+		SetRetryOptions(&models.RetryOptions{MaxRetries: 3, InitialDelay: 10 * time.Second, Backoff: models.BackoffFixed})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, err := range source.Events(ctx) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(connected) != 2 {
+		t.Fatalf("Expected a reconnection after the retry field, got %d connections", len(connected))
+	}
+	if delay := connected[1].Sub(connected[0]); delay > 2*time.Second {
+		t.Errorf("Expected the retry field to set the reconnection time, waited %v", delay)
+	}
+}
+
+func TestEventSourceIDWithoutData(t *testing.T) {
+	var mu sync.Mutex
+	var lastEventIDs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		connection := len(lastEventIDs)
+		mu.Unlock()
+
+		switch connection {
+		case 1:
+			// The second block only moves the last event ID, the incomplete third one is discarded
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "retry: 10\nid: 1\ndata: a\n\nid: 2\n\nid: 3\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	source := infrastructure.NewClient().SetBaseURL(server.URL).NewEventSource("/events", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var ids []string
+	for event, err := range source.Events(ctx) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("Expected a single event with ID 1, got %v", ids)
+	}
+	if len(lastEventIDs) != 2 || lastEventIDs[1] != "2" || source.LastEventID() != "2" {
+		t.Errorf("Expected to reconnect with Last-Event-ID 2, got %v (last %q)", lastEventIDs, source.LastEventID())
+	}
+}
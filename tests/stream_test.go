@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestStreamResponse(t *testing.T) {
+	payload := strings.Repeat("0123456789", 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Intercepted") != "yes" {
+			t.Errorf("Expected request interceptor to run")
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	var lastLoaded, lastTotal int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		AddRequestInterceptor(func(req *http.Request) (*http.Request, error) {
+			req.Header.Set("X-Intercepted", "yes")
+			return req, nil
+		}).
+		SetDownloadProgress(func(loaded, total int64) {
+			lastLoaded, lastTotal = loaded, total
+		})
+
+	resp, err := client.GetStream(context.Background(), "/export", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if lastLoaded != 0 {
+		t.Errorf("Expected body not to be read before the caller reads it, got %d bytes", lastLoaded)
+	}
+
+	if resp.ContentLength != int64(len(payload)) {
+		t.Errorf("Expected content length %d, got %d", len(payload), resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected no read error, got %v", err)
+	}
+
+	if string(data) != payload {
+		t.Error("Expected streamed body to match payload")
+	}
+
+	if lastLoaded != int64(len(payload)) || lastTotal != int64(len(payload)) {
+		t.Errorf("Expected progress %d/%d, got %d/%d", len(payload), len(payload), lastLoaded, lastTotal)
+	}
+}
+
+func TestStreamStatusValidationAndRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no such export"))
+			return
+		}
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ready"))
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   3,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	resp, err := client.R().Path("/export").DoStream(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(data) != "ready" || attempts != 3 {
+		t.Errorf("Expected body after 3 attempts, got %q after %d", data, attempts)
+	}
+
+	_, err = client.GetStream(context.Background(), "/missing", nil)
+	httpErr, ok := err.(*errors.HTTPError)
+	if !ok {
+		t.Fatalf("Expected HTTPError, got %T", err)
+	}
+
+	if httpErr.StatusCode != http.StatusNotFound || string(httpErr.Body) != "no such export" {
+		t.Errorf("Expected 404 with body, got %d %q", httpErr.StatusCode, httpErr.Body)
+	}
+}
+
+func TestStreamTimeoutCoversHeadersOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for i := 0; i < 4; i++ {
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetTimeout(100 * time.Millisecond)
+
+	resp, err := client.GetStream(context.Background(), "/slow-body", nil)
+	if err != nil {
+		t.Fatalf("Expected headers within timeout, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected slow body to be readable past the timeout, got %v", err)
+	}
+	if string(data) != strings.Repeat("chunk", 4) {
+		t.Errorf("Expected all chunks, got %q", data)
+	}
+
+	if _, err := client.GetStream(context.Background(), "/slow-headers", nil); err == nil {
+		t.Error("Expected timeout waiting for headers")
+	}
+}
@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// tusServer is a minimal in-process tus 1.0 server with the creation and
+// termination extensions. failPatches makes that many PATCH requests store
+// only half of their chunk and then drop the connection.
+type tusServer struct {
+	mu          sync.Mutex
+	uploads     map[string]*tusServerUpload
+	nextID      int
+	failPatches int
+	methods     []string
+}
+
+type tusServerUpload struct {
+	length   int64
+	data     []byte
+	metadata string
+}
+
+func newTusServer() *tusServer {
+	return &tusServer{uploads: make(map[string]*tusServerUpload)}
+}
+
+func (s *tusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods = append(s.methods, r.Method)
+
+	w.Header().Set("Tus-Resumable", "1.0.0")
+	if r.Header.Get("Tus-Resumable") != "1.0.0" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/files" {
+		length, _ := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &tusServerUpload{length: length, metadata: r.Header.Get("Upload-Metadata")}
+		w.Header().Set("Location", "files/"+id)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	upload, ok := s.uploads[strings.TrimPrefix(r.URL.Path, "/files/")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.length, 10))
+		w.Header().Set("Cache-Control", "no-store")
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if r.Header.Get("Upload-Offset") != strconv.Itoa(len(upload.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		chunk, _ := io.ReadAll(r.Body)
+		if s.failPatches > 0 {
+			s.failPatches--
+			upload.data = append(upload.data, chunk[:len(chunk)/2]...)
+			panic(http.ErrAbortHandler)
+		}
+		upload.data = append(upload.data, chunk...)
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(s.uploads, strings.TrimPrefix(r.URL.Path, "/files/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestTusUploadSendsChunks(t *testing.T) {
+	handler := newTusServer()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("tus!"), 2500)
+	var last [2]int64
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetUploadProgress(func(sent, total int64) {
+			if sent < last[0] {
+				t.Errorf("Expected progress to grow, got %d after %d", sent, last[0])
+			}
+			last = [2]int64{sent, total}
+		})
+
+	upload := client.NewTusUpload("/files", bytes.NewReader(content), int64(len(content))).
+		SetMetadata("filename", "clip.mp4").
+		SetMetadata("filetype", "video/mp4").
+		SetChunkSize(3000)
+
+	if err := upload.Upload(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if upload.URL() != server.URL+"/files/1" || upload.Offset() != int64(len(content)) {
+		t.Errorf("Unexpected upload state: %s at %d", upload.URL(), upload.Offset())
+	}
+
+	stored := handler.uploads["1"]
+	if !bytes.Equal(stored.data, content) {
+		t.Errorf("Expected server to store the content, got %d bytes", len(stored.data))
+	}
+
+	expectedMetadata := "filename " + base64.StdEncoding.EncodeToString([]byte("clip.mp4")) +
+		",filetype " + base64.StdEncoding.EncodeToString([]byte("video/mp4"))
+	if stored.metadata != expectedMetadata {
+		t.Errorf("Expected metadata %q, got %q", expectedMetadata, stored.metadata)
+	}
+
+	if fmt.Sprint(handler.methods) != "[POST PATCH PATCH PATCH PATCH]" {
+		t.Errorf("Unexpected requests: %v", handler.methods)
+	}
+
+	if last[0] != int64(len(content)) || last[1] != int64(len(content)) {
+		t.Errorf("Expected progress over the whole upload, got %v", last)
+	}
+}
+
+func TestTusUploadRecoversOffsetAfterFailure(t *testing.T) {
+	handler := newTusServer()
+	handler.failPatches = 1
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	upload := client.NewTusUpload("/files", bytes.NewReader(content), int64(len(content))).
+		SetChunkSize(4000).
+		SetRetryOptions(&models.RetryOptions{
+			MaxRetries:   3,
+			InitialDelay: 10 * time.Millisecond,
+			Backoff:      models.BackoffFixed,
+		})
+
+	if err := upload.Upload(context.Background()); err != nil {
+		t.Fatalf("Expected upload to recover, got %v", err)
+	}
+
+	if !bytes.Equal(handler.uploads["1"].data, content) {
+		t.Error("Expected server to store the content")
+	}
+
+	// The half-stored chunk is resumed after a HEAD instead of being resent
+	if fmt.Sprint(handler.methods) != "[POST PATCH HEAD PATCH PATCH]" {
+		t.Errorf("Unexpected requests: %v", handler.methods)
+	}
+}
+
+func TestTusUploadResumesFromURL(t *testing.T) {
+	handler := newTusServer()
+	handler.failPatches = 1
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("x"), 5000)
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	first := client.NewTusUpload("/files", bytes.NewReader(content), int64(len(content)))
+	if err := first.Upload(context.Background()); err == nil {
+		t.Fatal("Expected first upload to fail")
+	}
+
+	resumed := client.NewTusUpload("/files", bytes.NewReader(content), int64(len(content))).
+		SetURL(first.URL())
+	if err := resumed.Upload(context.Background()); err != nil {
+		t.Fatalf("Expected resumed upload to succeed, got %v", err)
+	}
+
+	if len(handler.uploads) != 1 || !bytes.Equal(handler.uploads["1"].data, content) {
+		t.Error("Expected the original upload to be completed")
+	}
+}
+
+func TestTusUploadTerminate(t *testing.T) {
+	handler := newTusServer()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	content := []byte("short lived")
+	upload := client.NewTusUpload("/files", bytes.NewReader(content), int64(len(content)))
+
+	if err := upload.Terminate(context.Background()); err == nil {
+		t.Error("Expected error terminating an upload that was not created")
+	}
+
+	if err := upload.Upload(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := upload.Terminate(context.Background()); err != nil {
+		t.Fatalf("Expected termination to succeed, got %v", err)
+	}
+
+	if len(handler.uploads) != 0 || upload.URL() != "" {
+		t.Error("Expected upload to be deleted")
+	}
+}
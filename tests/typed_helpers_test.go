@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fourth-ally/gofetch"
+	"github.com/fourth-ally/gofetch/domain/errors"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestTypedGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total", "2")
+		json.NewEncoder(w).Encode([]TestUser{{ID: 1, Name: "One"}, {ID: 2, Name: "Two"}})
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	resp, err := gofetch.Get[[]TestUser](context.Background(), client, "/users", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != 200 || resp.Headers.Get("X-Total") != "2" {
+		t.Errorf("Expected status and headers to be kept, got %d %v", resp.StatusCode, resp.Headers)
+	}
+
+	if len(resp.Data) != 2 || resp.Data[1].Name != "Two" {
+		t.Errorf("Expected typed users, got %+v", resp.Data)
+	}
+
+	if len(resp.RawBody) == 0 {
+		t.Error("Expected raw body to be kept")
+	}
+}
+
+func TestTypedPostAndDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user TestUser
+		json.NewDecoder(r.Body).Decode(&user)
+		user.ID = 9
+		json.NewEncoder(w).Encode(user)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	created, err := gofetch.Post[TestUser](context.Background(), client, "/users", nil, TestUser{Name: "Typed"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Data.ID != 9 || created.Data.Name != "Typed" {
+		t.Errorf("Expected created user, got %+v", created.Data)
+	}
+
+	req := client.R().Method("REPORT").Path("/users").Body(TestUser{Name: "Report"})
+	reported, err := gofetch.Do[TestUser](context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reported.Data.Name != "Report" {
+		t.Errorf("Expected typed response from Do, got %+v", reported.Data)
+	}
+	if req.GetMethod() != "REPORT" {
+		t.Error("Expected request to be reusable after Do")
+	}
+}
+
+func TestTypedErrorPassthrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+
+	resp, err := gofetch.Delete[map[string]interface{}](context.Background(), client, "/users/1", nil)
+	if resp != nil {
+		t.Error("Expected nil response on error")
+	}
+
+	if _, ok := err.(*errors.HTTPError); !ok {
+		t.Fatalf("Expected HTTPError, got %T", err)
+	}
+}
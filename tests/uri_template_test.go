@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// templateVariables are the example variables of RFC 6570, section 3.2.
+var templateVariables = map[string]interface{}{
+	"count":      []string{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []string{"red", "green", "blue"},
+	"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+	"v":          6,
+	"x":          1024,
+	"y":          768,
+	"empty":      "",
+	"empty_keys": map[string]string{},
+	"undef":      nil,
+}
+
+func TestExpandURITemplate(t *testing.T) {
+	// Associative arrays are expanded in sorted key order
+	tests := map[string]string{
+		// Level 1 and simple string expansion
+		"{var}":          "value",
+		"{hello}":        "Hello%20World%21",
+		"{half}":         "50%25",
+		"O{empty}X":      "OX",
+		"O{undef}X":      "OX",
+		"{x,y}":          "1024,768",
+		"{x,hello,y}":    "1024,Hello%20World%21,768",
+		"?{x,empty}":     "?1024,",
+		"?{x,undef}":     "?1024",
+		"?{undef,y}":     "?768",
+		"{var:3}":        "val",
+		"{var:30}":       "value",
+		"{list}":         "red,green,blue",
+		"{list*}":        "red,green,blue",
+		"{keys}":         "comma,%2C,dot,.,semi,%3B",
+		"{keys*}":        "comma=%2C,dot=.,semi=%3B",
+		"{base}index":    "http%3A%2F%2Fexample.com%2Fhome%2Findex",
+		"{count}":        "one,two,three",
+		"{dub}":          "me%2Ftoo",
+		"O{empty_keys}X": "OX",
+
+		// Reserved expansion
+		"{+var}":           "value",
+		"{+hello}":         "Hello%20World!",
+		"{+half}":          "50%25",
+		"{+base}index":     "http://example.com/home/index",
+		"{+path}/here":     "/foo/bar/here",
+		"here?ref={+path}": "here?ref=/foo/bar",
+		"{+path:6}/here":   "/foo/b/here",
+		"{+list}":          "red,green,blue",
+		"{+keys*}":         "comma=,,dot=.,semi=;",
+
+		// Fragment expansion
+		"{#var}":         "#value",
+		"{#hello}":       "#Hello%20World!",
+		"{#path:6}/here": "#/foo/b/here",
+		"{#keys}":        "#comma,,,dot,.,semi,;",
+
+		// Label expansion
+		"{.who}":         ".fred",
+		"{.who,who}":     ".fred.fred",
+		"{.half,who}":    ".50%25.fred",
+		"www{.dom*}":     "www.example.com",
+		"X{.var:3}":      "X.val",
+		"X{.list}":       "X.red,green,blue",
+		"X{.list*}":      "X.red.green.blue",
+		"X{.empty_keys}": "X",
+
+		// Path segment expansion
+		"{/who}":          "/fred",
+		"{/who,who}":      "/fred/fred",
+		"{/half,who}":     "/50%25/fred",
+		"{/who,dub}":      "/fred/me%2Ftoo",
+		"{/var,x}/here":   "/value/1024/here",
+		"{/var:1,var}":    "/v/value",
+		"{/list*,path:4}": "/red/green/blue/%2Ffoo",
+		"{/keys*}":        "/comma=%2C/dot=./semi=%3B",
+
+		// Path-style parameter expansion
+		"{;who}":         ";who=fred",
+		"{;half}":        ";half=50%25",
+		"{;empty}":       ";empty",
+		"{;v,empty,who}": ";v=6;empty;who=fred",
+		"{;x,y,undef}":   ";x=1024;y=768",
+		"{;hello:5}":     ";hello=Hello",
+		"{;list*}":       ";list=red;list=green;list=blue",
+		"{;keys*}":       ";comma=%2C;dot=.;semi=%3B",
+
+		// Form-style query expansion and continuation
+		"{?who}":         "?who=fred",
+		"{?empty}":       "?empty=",
+		"{?x,y,undef}":   "?x=1024&y=768",
+		"{?var:3}":       "?var=val",
+		"{?list}":        "?list=red,green,blue",
+		"{?list*}":       "?list=red&list=green&list=blue",
+		"{?keys}":        "?keys=comma,%2C,dot,.,semi,%3B",
+		"{?keys*}":       "?comma=%2C&dot=.&semi=%3B",
+		"{&who}":         "&who=fred",
+		"?fixed=yes{&x}": "?fixed=yes&x=1024",
+		"{&var:3}":       "&var=val",
+		"{&list*}":       "&list=red&list=green&list=blue",
+	}
+
+	for template, expected := range tests {
+		expanded, err := infrastructure.ExpandURITemplate(template, templateVariables)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", template, err)
+			continue
+		}
+		if expanded != expected {
+			t.Errorf("%s: expected %s, got %s", template, expected, expanded)
+		}
+	}
+}
+
+func TestExpandURITemplateErrors(t *testing.T) {
+	for _, template := range []string{"{var", "{}", "{?}", "{var:0}", "{var:abc}", "{a b}", "{=var}", "{keys:3}"} {
+		if _, err := infrastructure.ExpandURITemplate(template, templateVariables); err == nil {
+			t.Errorf("%s: expected an error", template)
+		}
+	}
+}
+
+func TestURITemplateAsRequestPath(t *testing.T) {
+	var requestURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().SetBaseURL(server.URL)
+	_, err := client.Get(context.Background(), "/repos{/owner,repo}/issues{?page,per_page}", map[string]interface{}{
+		"owner": "octo",
+		"repo":  "hello world",
+		"page":  2,
+		"state": "open",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Template variables are consumed, the remaining parameters extend the query
+	if requestURI != "/repos/octo/hello%20world/issues?page=2&state=open" {
+		t.Errorf("Unexpected request URI %s", requestURI)
+	}
+}
+
+func TestURITemplateWithPathParams(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	fullURL, err := client.R().
+		Path("https://api.example.com/users/{user}/repos{?type,sort}").
+		PathParam("user", "octo").
+		PathParam("sort", "updated").
+		URL()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fullURL != "https://api.example.com/users/octo/repos?sort=updated" {
+		t.Errorf("Unexpected URL %s", fullURL)
+	}
+
+	_, err = client.R().Path("/search{?q}").PathParam("q", "go").PathParam("page", 1).URL()
+	if err == nil {
+		t.Error("Expected an error for a path parameter missing from the template")
+	}
+}
+
+func TestBarePlaceholderInURITemplatePath(t *testing.T) {
+	client := infrastructure.NewClient()
+
+	// A defined value expands as the RFC 6570 simple expansion does
+	for _, name := range []string{"hello", "dub", "half"} {
+		template := "/files/{" + name + "}"
+		expected, err := infrastructure.ExpandURITemplate(template, templateVariables)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", template, err)
+		}
+		fullURL, err := client.R().Path(template).PathParam(name, templateVariables[name]).URL()
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", template, err)
+		}
+		if fullURL != expected {
+			t.Errorf("%s: expected %s, got %s", template, expected, fullURL)
+		}
+	}
+
+	// An undefined value fails instead of being omitted
+	_, err := client.R().Path("/files/{name}{?page}").Param("page", 1).URL()
+	if err == nil || !strings.Contains(err.Error(), `missing path parameter "name"`) {
+		t.Errorf("Expected a missing parameter error, got %v", err)
+	}
+}
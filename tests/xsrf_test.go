@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+func TestXSRFMirrorsCookieOnUnsafeMethods(t *testing.T) {
+	var headers sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: "token-1", Path: "/"})
+		}
+		headers.Store(r.Method, r.Header.Get("X-CSRFToken"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(infrastructure.NewCookieJar()).
+		SetXSRFOptions(&models.XSRFOptions{CookieName: "csrftoken", HeaderName: "X-CSRFToken"})
+
+	if _, err := client.Get(context.Background(), "/login", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.Post(context.Background(), "/items", nil, TestUser{Name: "Jane"}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.Delete(context.Background(), "/items/1", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got, _ := headers.Load(http.MethodGet); got != "" {
+		t.Errorf("Expected no token on GET, got %v", got)
+	}
+	if got, _ := headers.Load(http.MethodPost); got != "token-1" {
+		t.Errorf("Expected token on POST, got %v", got)
+	}
+	if got, _ := headers.Load(http.MethodDelete); got != "token-1" {
+		t.Errorf("Expected token on DELETE, got %v", got)
+	}
+}
+
+func TestXSRFKeepsExplicitHeader(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-XSRF-TOKEN")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	jar := infrastructure.NewCookieJar()
+	jar.SetCookies(mustParseURL(t, server.URL), []*http.Cookie{{Name: "XSRF-TOKEN", Value: "from-cookie"}})
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(jar).
+		SetXSRFOptions(&models.XSRFOptions{})
+
+	_, err := client.R().Method(http.MethodPut).Path("/items/1").Header("X-XSRF-TOKEN", "explicit").Do(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "explicit" {
+		t.Errorf("Expected explicit header to be kept, got %q", received)
+	}
+}
+
+func TestXSRFBootstrapAndRefresh(t *testing.T) {
+	var token atomic.Value
+	token.Store("token-1")
+	var bootstraps, attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/csrf":
+			bootstraps.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: token.Load().(string), Path: "/"})
+			w.WriteHeader(http.StatusNoContent)
+		case "/items":
+			attempts.Add(1)
+			if r.Header.Get("X-XSRF-TOKEN") != token.Load().(string) {
+				w.WriteHeader(419)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetCookieJar(infrastructure.NewCookieJar()).
+		SetXSRFOptions(&models.XSRFOptions{BootstrapPath: "/csrf"})
+
+	// The first unsafe request fetches the token
+	var user TestUser
+	if _, err := client.Post(context.Background(), "/items", nil, TestUser{Name: "Jane"}, &user); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bootstraps.Load() != 1 || attempts.Load() != 1 || user.Name != "Jane" {
+		t.Fatalf("Expected one bootstrap and one attempt, got %d and %d (%+v)", bootstraps.Load(), attempts.Load(), user)
+	}
+
+	// A rotated token is refreshed and the request replayed with its body
+	token.Store("token-2")
+	user = TestUser{}
+	if _, err := client.Post(context.Background(), "/items", nil, TestUser{Name: "John"}, &user); err != nil {
+		t.Fatalf("Expected request to be retried with the new token, got %v", err)
+	}
+	if bootstraps.Load() != 2 || attempts.Load() != 3 || user.Name != "John" {
+		t.Errorf("Expected a refresh and one replay, got %d bootstraps and %d attempts (%+v)", bootstraps.Load(), attempts.Load(), user)
+	}
+}
+
+func TestXSRFRefreshRetriesOnce(t *testing.T) {
+	var attempts atomic.Int32
+	var counter atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/csrf" {
+			w.Header().Set("X-XSRF-TOKEN", string(rune('a'+counter.Add(1))))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		attempts.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	// The token is taken from the bootstrap response header, no jar is needed
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetXSRFOptions(&models.XSRFOptions{BootstrapPath: "/csrf"})
+
+	_, err := client.Post(context.Background(), "/items", nil, TestUser{Name: "Jane"}, nil)
+	if err == nil {
+		t.Fatal("Expected the forbidden response to be returned")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected exactly one replay, got %d attempts", attempts.Load())
+	}
+}
+
+func TestXSRFTokenNotSentToOtherOrigins(t *testing.T) {
+	var received string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-XSRF-TOKEN")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-XSRF-TOKEN", "secret")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := infrastructure.NewClient().
+		SetBaseURL(server.URL).
+		SetXSRFOptions(&models.XSRFOptions{BootstrapPath: "/csrf"})
+
+	if _, err := client.Post(context.Background(), "/items", nil, nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The bootstrap token belongs to the base URL's origin
+	if _, err := client.Post(context.Background(), other.URL+"/collect", nil, nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "" {
+		t.Errorf("Expected no token for another origin, got %q", received)
+	}
+}
+
+func TestXSRFCookieNotSentCrossOrigin(t *testing.T) {
+	var received []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-XSRF-TOKEN"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer other.Close()
+
+	// The jar holds a token cookie for the other origin
+	jar := infrastructure.NewCookieJar()
+	otherURL, _ := url.Parse(other.URL)
+	jar.SetCookies(otherURL, []*http.Cookie{{Name: "XSRF-TOKEN", Value: "other-token", Path: "/"}})
+
+	withBase := infrastructure.NewClient().
+		SetBaseURL("https://api.example.com").
+		SetCookieJar(jar).
+		SetXSRFOptions(&models.XSRFOptions{})
+
+	withOrigins := infrastructure.NewClient().
+		SetCookieJar(jar).
+		SetXSRFOptions(&models.XSRFOptions{Origins: []string{"https://api.example.com"}})
+
+	for _, client := range []*infrastructure.Client{withBase, withOrigins} {
+		if _, err := client.Post(context.Background(), other.URL+"/collect", nil, nil, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	for _, token := range received {
+		if token != "" {
+			t.Errorf("Expected no token for a cross-origin URL, got %q", token)
+		}
+	}
+}
+
+func TestXSRFOriginsWithoutBaseURL(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-XSRF-TOKEN")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	jar := infrastructure.NewCookieJar()
+	serverURL, _ := url.Parse(server.URL)
+	jar.SetCookies(serverURL, []*http.Cookie{{Name: "XSRF-TOKEN", Value: "abc", Path: "/"}})
+
+	// Without a base URL or origins, the token would never be sent
+	options := &models.XSRFOptions{}
+	client := infrastructure.NewClient().SetCookieJar(jar).SetXSRFOptions(options)
+	if _, err := client.Post(context.Background(), server.URL+"/items", nil, nil, nil); err == nil || !strings.Contains(err.Error(), "Origins") {
+		t.Fatalf("Expected a configuration error, got %v", err)
+	}
+
+	options.Origins = []string{server.URL}
+	client.SetXSRFOptions(options)
+	if _, err := client.Post(context.Background(), server.URL+"/items", nil, nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received != "abc" {
+		t.Errorf("Expected the token for a listed origin, got %q", received)
+	}
+}
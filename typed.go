@@ -0,0 +1,87 @@
+package gofetch
+
+import (
+	"context"
+	"iter"
+
+	"github.com/fourth-ally/gofetch/domain/models"
+	"github.com/fourth-ally/gofetch/infrastructure"
+)
+
+// Response is a response whose data is decoded into T.
+type Response[T any] = models.TypedResponse[T]
+
+// Get performs a GET request and decodes the response body into T.
+//
+// Example:
+//
+//	resp, err := gofetch.Get[[]User](ctx, client, "/users", nil)
+//	users := resp.Data
+func Get[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, configs ...*models.Config) (*Response[T], error) {
+	var data T
+	resp, err := client.Get(ctx, path, params, &data, configs...)
+	return typedResponse(resp, data, err)
+}
+
+// Post performs a POST request and decodes the response body into T.
+func Post[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, body interface{}, configs ...*models.Config) (*Response[T], error) {
+	var data T
+	resp, err := client.Post(ctx, path, params, body, &data, configs...)
+	return typedResponse(resp, data, err)
+}
+
+// Put performs a PUT request and decodes the response body into T.
+func Put[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, body interface{}, configs ...*models.Config) (*Response[T], error) {
+	var data T
+	resp, err := client.Put(ctx, path, params, body, &data, configs...)
+	return typedResponse(resp, data, err)
+}
+
+// Patch performs a PATCH request and decodes the response body into T.
+func Patch[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, body interface{}, configs ...*models.Config) (*Response[T], error) {
+	var data T
+	resp, err := client.Patch(ctx, path, params, body, &data, configs...)
+	return typedResponse(resp, data, err)
+}
+
+// Delete performs a DELETE request and decodes the response body into T.
+func Delete[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, configs ...*models.Config) (*Response[T], error) {
+	var data T
+	resp, err := client.Delete(ctx, path, params, &data, configs...)
+	return typedResponse(resp, data, err)
+}
+
+// Do executes a built request and decodes the response body into T.
+// The request itself is left untouched, so it can be reused.
+func Do[T any](ctx context.Context, req *infrastructure.Request) (*Response[T], error) {
+	var data T
+	resp, err := req.Clone().Into(&data).Do(ctx)
+	return typedResponse(resp, data, err)
+}
+
+// typedResponse wraps a response and its decoded data, passing errors through.
+func typedResponse[T any](resp *models.Response, data T, err error) (*Response[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return models.NewTypedResponse(resp, data), nil
+}
+
+// GetNDJSON performs a GET request for a newline-delimited JSON response and
+// yields one decoded item per line, see infrastructure.StreamNDJSON.
+//
+// Example:
+//
+//	for user, err := range gofetch.GetNDJSON[User](ctx, client, "/users/export", nil) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(user.Name)
+//	}
+func GetNDJSON[T any](ctx context.Context, client *infrastructure.Client, path string, params map[string]interface{}, configs ...*models.Config) iter.Seq2[T, error] {
+	req := client.R().Path(path).Params(params)
+	for _, config := range configs {
+		req.Config(config)
+	}
+	return infrastructure.StreamNDJSON[T](ctx, req)
+}